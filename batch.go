@@ -0,0 +1,155 @@
+package redigo
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gomodule/redigo/redis"
+	"reflect"
+)
+
+// MGet reads the values of keys in one round trip.
+// dest must be a pointer to a map[string]T (or a non-nil map[string]T) or a pointer to a []T,
+// each value is decoded the same way as Get. For a slice the results keep the order of keys
+// and missing keys are left as zero values. Keys that do not exist are returned in missing
+// instead of failing the whole call with redis.ErrNil
+func (r *Redigo) MGet(keys []string, dest any) (missing []string, err error) {
+	val, err := batchDest(dest)
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return r.scanBatch(val, "key", nil, nil)
+	}
+
+	conn, err := r.getConn()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	var args = make([]any, 0, len(keys))
+	for _, key := range keys {
+		args = append(args, key)
+	}
+	values, err := redis.Values(conn.Do("MGET", args...))
+	if err != nil {
+		return nil, err
+	}
+	if len(values) != len(keys) {
+		return nil, fmt.Errorf("%w: MGET returned %d values for %d keys", ErrInvalidResponse, len(values), len(keys))
+	}
+	return r.scanBatch(val, "key", keys, values)
+}
+
+// batchDest 检查并初始化批量读取的目标: map[string]T或[]T
+func batchDest(dest any) (reflect.Value, error) {
+	val := reflect.ValueOf(dest)
+	if val.Kind() == reflect.Ptr && !val.IsNil() {
+		val = val.Elem()
+	}
+	switch val.Kind() {
+	case reflect.Map:
+		if val.Type().Key().Kind() != reflect.String {
+			return val, errors.New("dest map key must be a string")
+		}
+		if val.IsNil() {
+			if !val.CanSet() {
+				return val, errors.New("dest must be a non-nil map or a pointer to map")
+			}
+			val.Set(reflect.MakeMapWithSize(val.Type(), 0))
+		}
+	case reflect.Slice:
+		if !val.CanSet() {
+			return val, errors.New("dest must be a pointer to slice")
+		}
+	default:
+		return val, errors.New("dest must be a map[string]T or a pointer to []T")
+	}
+	return val, nil
+}
+
+// scanBatch 将批量读取的结果按names解码到目标中, nil结果记录在missing中
+func (r *Redigo) scanBatch(val reflect.Value, kind string, names []string, values []any) (missing []string, err error) {
+	if val.Kind() == reflect.Slice {
+		val.Set(reflect.MakeSlice(val.Type(), len(names), len(names)))
+	}
+	elemType := val.Type().Elem()
+	for i, reply := range values {
+		if reply == nil {
+			missing = append(missing, names[i])
+			continue
+		}
+		elem := reflect.New(elemType)
+		if err = r.scanReply(reply, elem.Interface()); err != nil {
+			return nil, fmt.Errorf("%s [%s]: %w", kind, names[i], err)
+		}
+		if val.Kind() == reflect.Map {
+			val.SetMapIndex(reflect.ValueOf(names[i]).Convert(val.Type().Key()), elem.Elem())
+		} else {
+			val.Index(i).Set(elem.Elem())
+		}
+	}
+	return missing, nil
+}
+
+// MSet sets all the key/value pairs of values (a map[string]T) in one round trip,
+// values are encoded the same way as Set so structs are stored as JSON
+func (r *Redigo) MSet(values any) error {
+	args, err := r.msetArgs(values)
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		return nil
+	}
+
+	conn, err := r.getConn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return checkOK(conn.Do("MSET", args...))
+}
+
+// MSetNX sets all the key/value pairs of values (a map[string]T) only if none of the keys exist,
+// it returns false when nothing was set because at least one key already exists
+func (r *Redigo) MSetNX(values any) (bool, error) {
+	args, err := r.msetArgs(values)
+	if err != nil {
+		return false, err
+	}
+	if len(args) == 0 {
+		return false, nil
+	}
+
+	conn, err := r.getConn()
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+
+	return redis.Bool(conn.Do("MSETNX", args...))
+}
+
+// msetArgs 将map[string]T展开为 key value key value ... 参数列表
+func (r *Redigo) msetArgs(values any) ([]any, error) {
+	val := reflect.ValueOf(values)
+	if val.Kind() == reflect.Ptr && !val.IsNil() {
+		val = val.Elem()
+	}
+	if val.Kind() != reflect.Map || val.Type().Key().Kind() != reflect.String {
+		return nil, errors.New("values must be a map[string]T")
+	}
+
+	var args = make([]any, 0, val.Len()*2)
+	iter := val.MapRange()
+	for iter.Next() {
+		data, err := r.encodeValue(iter.Value().Interface())
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, iter.Key().String(), data)
+	}
+	return args, nil
+}
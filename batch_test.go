@@ -0,0 +1,57 @@
+package redigo
+
+import (
+	"testing"
+)
+
+const (
+	redigoBatchKey1 = "redigoBatchKey1"
+	redigoBatchKey2 = "redigoBatchKey2"
+)
+
+func TestRedigo_MSetMGet(t *testing.T) {
+	redigo := NewRedigo(opts...)
+	_, err := redigo.Del(redigoNotFoundKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = redigo.MSet(map[string]*User{
+		redigoBatchKey1: {ID: 10086, Name: "dianxin"},
+		redigoBatchKey2: {ID: 10010, Name: "liantong"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var users = make(map[string]User)
+	missing, err := redigo.MGet([]string{redigoBatchKey1, redigoNotFoundKey, redigoBatchKey2}, users)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 2 || users[redigoBatchKey2].Name != "liantong" {
+		t.Fatalf("unexpected MGET result %+v", users)
+	}
+	if len(missing) != 1 || missing[0] != redigoNotFoundKey {
+		t.Fatalf("unexpected missing keys %+v", missing)
+	}
+
+	var list []*User
+	_, err = redigo.MGet([]string{redigoNotFoundKey, redigoBatchKey1}, &list)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 || list[0] != nil || list[1].ID != 10086 {
+		t.Fatalf("unexpected MGET slice result %+v", list)
+	}
+
+	ok, err := redigo.MSetNX(map[string]string{redigoBatchKey1: "exists", redigoNotFoundKey: "new"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok {
+		t.Fatal("MSETNX should not set any key when one of them exists")
+	}
+	_, _ = redigo.Del(redigoBatchKey1)
+	_, _ = redigo.Del(redigoBatchKey2)
+}
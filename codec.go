@@ -0,0 +1,133 @@
+package redigo
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"github.com/vmihailenco/msgpack/v5"
+	"sync"
+)
+
+const (
+	CodecJSON    = "json"
+	CodecGob     = "gob"
+	CodecMsgpack = "msgpack"
+)
+
+// Codec encodes the values which are not of a basic type (string, number, bool or []byte)
+// written by Set, MSet, ListPush... and decodes them in Get, MGet, ListPop...
+type Codec interface {
+	// Name returns the name the codec is registered with
+	Name() string
+	// Marshal encodes v
+	Marshal(v any) ([]byte, error)
+	// Unmarshal decodes data into the pointer v
+	Unmarshal(data []byte, v any) error
+}
+
+type jsonCodec struct{}
+
+// JSONCodec returns the codec using encoding/json, the default codec
+func JSONCodec() Codec {
+	return jsonCodec{}
+}
+
+func (jsonCodec) Name() string {
+	return CodecJSON
+}
+
+func (jsonCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+type gobCodec struct{}
+
+// GobCodec returns the codec using encoding/gob, values are only readable by Go clients
+func GobCodec() Codec {
+	return gobCodec{}
+}
+
+func (gobCodec) Name() string {
+	return CodecGob
+}
+
+func (gobCodec) Marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (gobCodec) Unmarshal(data []byte, v any) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+type msgpackCodec struct{}
+
+// MsgpackCodec returns the codec using MessagePack, which is more compact than JSON
+func MsgpackCodec() Codec {
+	return msgpackCodec{}
+}
+
+func (msgpackCodec) Name() string {
+	return CodecMsgpack
+}
+
+func (msgpackCodec) Marshal(v any) ([]byte, error) {
+	return msgpack.Marshal(v)
+}
+
+func (msgpackCodec) Unmarshal(data []byte, v any) error {
+	return msgpack.Unmarshal(data, v)
+}
+
+// codecs 已注册的编解码器
+var codecs = struct {
+	locker sync.RWMutex
+	byName map[string]Codec
+}{
+	byName: map[string]Codec{
+		CodecJSON:    JSONCodec(),
+		CodecGob:     GobCodec(),
+		CodecMsgpack: MsgpackCodec(),
+	},
+}
+
+// RegisterCodec registers a custom codec (e.g. protobuf) by its name, replacing any codec of the same name
+func RegisterCodec(c Codec) {
+	codecs.locker.Lock()
+	defer codecs.locker.Unlock()
+	codecs.byName[c.Name()] = c
+}
+
+// LookupCodec returns the codec registered with name, the built-in codecs are always registered
+func LookupCodec(name string) (Codec, bool) {
+	codecs.locker.RLock()
+	defer codecs.locker.RUnlock()
+	c, ok := codecs.byName[name]
+	return c, ok
+}
+
+// WithCodec returns a shallow copy of the client encoding and decoding the values with c, it shares
+// the connection pool of r. It is used per call to read values written by other services, e.g.
+// r.WithCodec(MsgpackCodec()).Get(key, &v)
+func (r *Redigo) WithCodec(c Codec) *Redigo {
+	options := *r.options
+	options.codec = c
+	cp := *r
+	cp.options = &options
+	return &cp
+}
+
+// codec 返回当前使用的编解码器, 默认JSON
+func (r *Redigo) codec() Codec {
+	if r.options.codec == nil {
+		return jsonCodec{}
+	}
+	return r.options.codec
+}
@@ -0,0 +1,66 @@
+package redigo
+
+import (
+	"reflect"
+	"testing"
+)
+
+const (
+	redigoCodecKey = "redigoCodecKey"
+)
+
+// testCodec 自定义编解码器, 用于测试注册表
+type testCodec struct {
+	jsonCodec
+}
+
+func (testCodec) Name() string {
+	return "test"
+}
+
+func TestRedigo_Codec(t *testing.T) {
+	user := User{ID: 1, Name: "lory"}
+
+	for _, name := range []string{CodecJSON, CodecGob, CodecMsgpack} {
+		codec, ok := LookupCodec(name)
+		if !ok {
+			t.Fatalf("codec %s not registered", name)
+		}
+		redigo := NewRedigo(append(opts, WithCodec(codec))...)
+		if err := redigo.Set(redigoCodecKey, &user); err != nil {
+			t.Fatal(err)
+		}
+		var got User
+		if err := redigo.Get(redigoCodecKey, &got); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !reflect.DeepEqual(got, user) {
+			t.Fatalf("%s: expected %+v, got %+v", name, user, got)
+		}
+	}
+
+	// 单次调用使用其他编解码器读取
+	redigo := NewRedigo(opts...)
+	if err := redigo.WithCodec(MsgpackCodec()).Set(redigoCodecKey, &user); err != nil {
+		t.Fatal(err)
+	}
+	var got User
+	if err := redigo.Get(redigoCodecKey, &got); err == nil {
+		t.Fatal("expected JSON decoding of a msgpack value to fail")
+	}
+	if err := redigo.WithCodec(MsgpackCodec()).Get(redigoCodecKey, &got); err != nil || !reflect.DeepEqual(got, user) {
+		t.Fatalf("expected %+v, got %+v (%v)", user, got, err)
+	}
+
+	RegisterCodec(testCodec{})
+	codec, ok := LookupCodec("test")
+	if !ok {
+		t.Fatal("custom codec not registered")
+	}
+	if err := redigo.WithCodec(codec).Set(redigoCodecKey, &user); err != nil {
+		t.Fatal(err)
+	}
+	if err := redigo.Get(redigoCodecKey, &got); err != nil || !reflect.DeepEqual(got, user) {
+		t.Fatalf("expected %+v, got %+v (%v)", user, got, err)
+	}
+}
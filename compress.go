@@ -0,0 +1,160 @@
+package redigo
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"sync"
+)
+
+const (
+	CompressorGzip  byte = 1
+	CompressorFlate byte = 2
+)
+
+// compressedMagic starts the header of a compressed value, followed by the ID of the compressor
+var compressedMagic = []byte{0x00, 'r', 'c'}
+
+// Compressor compresses the values written when WithCompression is set. The compressed values
+// are prefixed by a header holding the compressor ID, so they are decompressed automatically
+// by the compressor registered with that ID whatever the options of the reader
+type Compressor interface {
+	// ID returns the identifier stored in the header, the IDs below 16 are reserved
+	ID() byte
+	// Compress compresses data
+	Compress(data []byte) ([]byte, error)
+	// Decompress decompresses data
+	Decompress(data []byte) ([]byte, error)
+}
+
+type gzipCompressor struct {
+	level int
+}
+
+// GzipCompressor returns the compressor using compress/gzip with level (e.g. gzip.DefaultCompression)
+func GzipCompressor(level int) Compressor {
+	return gzipCompressor{level: level}
+}
+
+func (gzipCompressor) ID() byte {
+	return CompressorGzip
+}
+
+func (c gzipCompressor) Compress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w, err := gzip.NewWriterLevel(&buf, c.level)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = w.Write(data); err != nil {
+		return nil, err
+	}
+	if err = w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (gzipCompressor) Decompress(data []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
+type flateCompressor struct {
+	level int
+}
+
+// FlateCompressor returns the compressor using compress/flate with level (e.g. flate.DefaultCompression),
+// its output is slightly smaller than gzip
+func FlateCompressor(level int) Compressor {
+	return flateCompressor{level: level}
+}
+
+func (flateCompressor) ID() byte {
+	return CompressorFlate
+}
+
+func (c flateCompressor) Compress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w, err := flate.NewWriter(&buf, c.level)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = w.Write(data); err != nil {
+		return nil, err
+	}
+	if err = w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (flateCompressor) Decompress(data []byte) ([]byte, error) {
+	r := flate.NewReader(bytes.NewReader(data))
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
+// compressors 按ID注册的压缩器, 读取时根据头部的ID解压
+var compressors = struct {
+	locker sync.RWMutex
+	byID   map[byte]Compressor
+}{
+	byID: map[byte]Compressor{
+		CompressorGzip:  GzipCompressor(gzip.DefaultCompression),
+		CompressorFlate: FlateCompressor(flate.DefaultCompression),
+	},
+}
+
+// RegisterCompressor registers a custom compressor by its ID so the values it compressed can be read
+func RegisterCompressor(c Compressor) {
+	compressors.locker.Lock()
+	defer compressors.locker.Unlock()
+	compressors.byID[c.ID()] = c
+}
+
+// LookupCompressor returns the compressor registered with id
+func LookupCompressor(id byte) (Compressor, bool) {
+	compressors.locker.RLock()
+	defer compressors.locker.RUnlock()
+	c, ok := compressors.byID[id]
+	return c, ok
+}
+
+// compress 超过阈值且压缩后更小时返回带头部的压缩数据, 否则原样返回
+func (r *Redigo) compress(data []byte) ([]byte, error) {
+	c := r.options.compressor
+	if c == nil || len(data) < r.options.compressThreshold {
+		return data, nil
+	}
+	compressed, err := c.Compress(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(compressed)+len(compressedMagic)+1 >= len(data) {
+		return data, nil
+	}
+	out := make([]byte, 0, len(compressedMagic)+1+len(compressed))
+	out = append(out, compressedMagic...)
+	out = append(out, c.ID())
+	return append(out, compressed...), nil
+}
+
+// decompress 检测头部并解压, 未压缩的数据原样返回
+func decompress(data []byte) ([]byte, error) {
+	n := len(compressedMagic)
+	if len(data) <= n || !bytes.Equal(data[:n], compressedMagic) {
+		return data, nil
+	}
+	c, ok := LookupCompressor(data[n])
+	if !ok {
+		return nil, fmt.Errorf("%w: %d", ErrUnknownCompressor, data[n])
+	}
+	return c.Decompress(data[n+1:])
+}
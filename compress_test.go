@@ -0,0 +1,59 @@
+package redigo
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"github.com/gomodule/redigo/redis"
+	"strings"
+	"testing"
+)
+
+const (
+	redigoCompressKey = "redigoCompressKey"
+)
+
+func TestRedigo_Compression(t *testing.T) {
+	large := strings.Repeat("redigo compression ", 100)
+	reader := NewRedigo(opts...)
+
+	for _, c := range []Compressor{GzipCompressor(gzip.BestSpeed), FlateCompressor(flate.DefaultCompression)} {
+		redigo := NewRedigo(append(opts, WithCompression(c, 1024))...)
+
+		// 超过阈值的值压缩存储, 未配置压缩的客户端也能读取
+		users := []User{{ID: 1, Name: large}, {ID: 2, Name: large}}
+		if err := redigo.Set(redigoCompressKey, users); err != nil {
+			t.Fatal(err)
+		}
+		raw, err := redis.Bytes(redigo.Do("GET", redigoCompressKey))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.HasPrefix(raw, append(compressedMagic, c.ID())) || len(raw) >= len(large) {
+			t.Fatalf("expected compressed value, got %d bytes", len(raw))
+		}
+		var got []User
+		if err = reader.Get(redigoCompressKey, &got); err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != 2 || got[1].Name != large {
+			t.Fatalf("unexpected value %+v", got)
+		}
+
+		if err = redigo.Set(redigoCompressKey, large); err != nil {
+			t.Fatal(err)
+		}
+		var s string
+		if err = reader.Get(redigoCompressKey, &s); err != nil || s != large {
+			t.Fatalf("unexpected value %q (%v)", s, err)
+		}
+
+		// 小于阈值的值原样存储
+		if err = redigo.Set(redigoCompressKey, "small"); err != nil {
+			t.Fatal(err)
+		}
+		if raw, _ = redis.Bytes(redigo.Do("GET", redigoCompressKey)); string(raw) != "small" {
+			t.Fatalf("expected raw value, got %q", raw)
+		}
+	}
+}
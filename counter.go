@@ -0,0 +1,142 @@
+package redigo
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gomodule/redigo/redis"
+	"strconv"
+)
+
+// counterScript increments the counter within the bounds and sets the expiry when it is created.
+// It returns {1, new value} on success and {0, current value} when a bound would be exceeded
+var counterScript = NewScript(1, `
+	local value = redis.call("GET", KEYS[1])
+	local cur = 0
+	if value then
+		cur = tonumber(value)
+		if cur == nil then
+			return redis.error_reply("ERR value is not an integer or out of range")
+		end
+	end
+	local delta = tonumber(ARGV[1])
+	if ARGV[2] ~= "" and cur + delta > tonumber(ARGV[2]) then
+		return {0, cur}
+	end
+	if ARGV[3] ~= "" and cur + delta < tonumber(ARGV[3]) then
+		return {0, cur}
+	end
+	local ret = redis.call("INCRBY", KEYS[1], delta)
+	if not value and tonumber(ARGV[4]) > 0 then
+		redis.call("PEXPIRE", KEYS[1], ARGV[4])
+	end
+	return {1, ret}
+`)
+
+// Incr increments the integer value of key by one and returns the new value
+func (r *Redigo) Incr(key string) (int64, error) {
+	return r.intCmd("INCR", key)
+}
+
+// IncrBy increments the integer value of key by delta and returns the new value
+func (r *Redigo) IncrBy(key string, delta int64) (int64, error) {
+	return r.intCmd("INCRBY", key, delta)
+}
+
+// IncrByFloat increments the float value of key by delta and returns the new value
+func (r *Redigo) IncrByFloat(key string, delta float64) (float64, error) {
+	var f float64
+	if err := r.getInto(&f, "INCRBYFLOAT", key, delta); err != nil {
+		return 0, err
+	}
+	return f, nil
+}
+
+// Decr decrements the integer value of key by one and returns the new value
+func (r *Redigo) Decr(key string) (int64, error) {
+	return r.intCmd("DECR", key)
+}
+
+// DecrBy decrements the integer value of key by delta and returns the new value
+func (r *Redigo) DecrBy(key string, delta int64) (int64, error) {
+	return r.intCmd("DECRBY", key, delta)
+}
+
+// Counter is an integer counter stored at a key, optionally bounded and expiring from its creation
+type Counter struct {
+	redigo  *Redigo
+	key     string
+	options *counterOptions
+}
+
+// Counter returns a counter handle of key, no command is sent until it is used
+func (r *Redigo) Counter(key string, opts ...CounterOption) *Counter {
+	return &Counter{
+		redigo:  r,
+		key:     key,
+		options: parseCounterOptions(opts...),
+	}
+}
+
+// Key returns the key of the counter
+func (c *Counter) Key() string {
+	return c.key
+}
+
+// Incr increments the counter by one, see IncrBy
+func (c *Counter) Incr() (int64, error) {
+	return c.IncrBy(1)
+}
+
+// Decr decrements the counter by one, see IncrBy
+func (c *Counter) Decr() (int64, error) {
+	return c.IncrBy(-1)
+}
+
+// DecrBy decrements the counter by delta, see IncrBy
+func (c *Counter) DecrBy(delta int64) (int64, error) {
+	return c.IncrBy(-delta)
+}
+
+// IncrBy increments the counter by delta atomically and returns the new value.
+// The expiry is set when the counter is created by this call, and ErrCounterLimitExceeded
+// is returned without changing the counter when the result would exceed the max/min bound
+func (c *Counter) IncrBy(delta int64) (int64, error) {
+	var max, min string
+	if c.options.max != nil {
+		max = strconv.FormatInt(*c.options.max, 10)
+	}
+	if c.options.min != nil {
+		min = strconv.FormatInt(*c.options.min, 10)
+	}
+	reply, err := c.redigo.evalScript(counterScript, c.key, delta, max, min, c.options.expiry.Milliseconds())
+	if err != nil {
+		return 0, err
+	}
+	values, err := redis.Int64s(reply, nil)
+	if err != nil {
+		return 0, err
+	}
+	if len(values) != 2 {
+		return 0, fmt.Errorf("%w: %v", ErrInvalidResponse, reply)
+	}
+	if values[0] == 0 {
+		return values[1], ErrCounterLimitExceeded
+	}
+	return values[1], nil
+}
+
+// Get returns the value of the counter, 0 if it does not exist
+func (c *Counter) Get() (int64, error) {
+	var n int64
+	err := c.redigo.Get(c.key, &n)
+	if err != nil && !errors.Is(err, redis.ErrNil) {
+		return 0, err
+	}
+	return n, nil
+}
+
+// Reset deletes the counter
+func (c *Counter) Reset() error {
+	_, err := c.redigo.Del(c.key)
+	return err
+}
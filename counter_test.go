@@ -0,0 +1,64 @@
+package redigo
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+const (
+	redigoCounterKey = "redigoCounterKey"
+)
+
+func TestRedigo_Incr(t *testing.T) {
+	redigo := NewRedigo(opts...)
+	_, err := redigo.Del(redigoCounterKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := redigo.IncrBy(redigoCounterKey, 10)
+	if err != nil || n != 10 {
+		t.Fatalf("INCRBY returned %v %v", n, err)
+	}
+	n, err = redigo.DecrBy(redigoCounterKey, 3)
+	if err != nil || n != 7 {
+		t.Fatalf("DECRBY returned %v %v", n, err)
+	}
+	f, err := redigo.IncrByFloat(redigoCounterKey, 0.5)
+	if err != nil || f != 7.5 {
+		t.Fatalf("INCRBYFLOAT returned %v %v", f, err)
+	}
+	if _, err = redigo.Incr(redigoCounterKey); err == nil {
+		t.Fatal("expected error when incrementing a float value")
+	}
+}
+
+func TestRedigo_Counter(t *testing.T) {
+	redigo := NewRedigo(opts...)
+	counter := redigo.Counter(redigoCounterKey, WithCounterExpiry(time.Minute), WithCounterMax(3))
+	if err := counter.Reset(); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := int64(1); i <= 3; i++ {
+		n, err := counter.Incr()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != i {
+			t.Fatalf("expected counter %d, got %d", i, n)
+		}
+	}
+	n, err := counter.Incr()
+	if !errors.Is(err, ErrCounterLimitExceeded) || n != 3 {
+		t.Fatalf("expected ErrCounterLimitExceeded at 3, got %v %v", n, err)
+	}
+	ttl, err := redigo.PTTL(redigoCounterKey)
+	if err != nil || ttl <= 0 {
+		t.Fatalf("counter expiry not set: %v %v", ttl, err)
+	}
+	if n, err = counter.Get(); err != nil || n != 3 {
+		t.Fatalf("counter GET returned %v %v", n, err)
+	}
+}
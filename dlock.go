@@ -4,18 +4,69 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/gomodule/redigo/redis"
+	"slices"
+	"sync"
 	"time"
 )
 
 // Lock represents a distributed lock
 type Lock struct {
-	key      string
-	value    string
-	expiry   int64
-	redigo   *Redigo
+	key    string
+	value  string
+	token  int64
+	redigo *Redigo
+
+	locker   sync.Mutex
+	expiry   time.Duration
 	acquired bool
+	stop     chan struct{}      // closed to stop the watchdog
+	reset    chan time.Duration // renew interval after Extend, nil if the interval was set explicitly
+	lost     chan struct{}      // closed when the lease is lost
+	lostOnce sync.Once
 }
 
+// lockScript sets the lock if it does not exist and increments its fencing counter (KEYS[2]) atomically,
+// it is used instead of SET NX PX with WithFencing. It returns the new fencing token, 0 if the lock is held by someone else
+var lockScript = NewScript(2, `
+	if redis.call("SET", KEYS[1], ARGV[1], "NX", "PX", ARGV[2]) then
+		return redis.call("INCR", KEYS[2])
+	end
+	return 0
+`)
+
+// unlockScript checks if the lock is still held by us and deletes it atomically,
+// then publishes the key to the channel ARGV[2] so the waiters retry immediately
+var unlockScript = NewScript(1, `
+	if redis.call("GET", KEYS[1]) == ARGV[1] then
+		redis.call("DEL", KEYS[1])
+		redis.call("PUBLISH", ARGV[2], KEYS[1])
+		return 1
+	else
+		return 0
+	end
+`)
+
+// extendScript resets the expiry (in milliseconds) of the lock if it is still held by us
+var extendScript = NewScript(1, `
+	if redis.call("GET", KEYS[1]) == ARGV[1] then
+		return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+	else
+		return 0
+	end
+`)
+
+// pttlScript returns the remaining time to live (in milliseconds) of the lock if it is still held by us, -3 otherwise
+var pttlScript = NewScript(1, `
+	if redis.call("GET", KEYS[1]) == ARGV[1] then
+		return redis.call("PTTL", KEYS[1])
+	else
+		return -3
+	end
+`)
+
 // randomValue generates a random string for lock value
 func randomValue() (string, error) {
 	b := make([]byte, 16)
@@ -29,114 +80,213 @@ func randomValue() (string, error) {
 // BlockLock acquires a distributed lock in blocking mode
 // It returns an unlock function and an error if failed to acquire lock
 func (r *Redigo) BlockLock(key string, expiry time.Duration) (func() error, error) {
-	value, err := randomValue()
+	lock, err := r.Obtain(key, expiry, 0)
 	if err != nil {
 		return nil, err
 	}
+	return lock.Unlock, nil
+}
 
-	for {
-		acquired, err := r.tryLock(key, value, expiry)
+// TryLock tries to acquire a distributed lock with a timeout
+// It returns an unlock function and an error if failed to acquire lock within timeout
+func (r *Redigo) TryLock(key string, expiry time.Duration, timeout time.Duration) (func() error, error) {
+	lock, err := r.Obtain(key, expiry, timeout)
+	if err != nil {
+		return nil, err
+	}
+	return lock.Unlock, nil
+}
+
+// Obtain acquires a distributed lock and returns its handle.
+// It blocks until the lock is acquired if timeout is not positive, otherwise it returns
+// ErrLockAcquisitionFailed when the lock could not be acquired within timeout.
+// With WithWatchdog the lease is renewed in the background until Unlock
+func (r *Redigo) Obtain(key string, expiry, timeout time.Duration, opts ...LockOption) (*Lock, error) {
+	var ctx = context.Background()
+	var interval = 100 * time.Millisecond
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+		interval = 50 * time.Millisecond
+	}
+	opts = append([]LockOption{WithRetryStrategy(FixedRetry(interval))}, opts...)
+	return r.LockContext(ctx, key, append(opts, WithLockExpiry(expiry))...)
+}
+
+// LockContext acquires a distributed lock, retrying according to WithRetryStrategy
+// (exponential backoff with jitter by default) until ctx is done or WithMaxAttempts is reached,
+// in which case an error wrapping ErrLockAcquisitionFailed is returned
+func (r *Redigo) LockContext(ctx context.Context, key string, opts ...LockOption) (*Lock, error) {
+	options := parseLockOptions(opts...)
+
+	var value string
+	var token int64
+	err := r.acquire(ctx, key, options, func(ctx context.Context) (bool, error) {
+		var err error
+		if value, err = newLockValue(options); err != nil {
+			return false, err
+		}
+		var acquired bool
+		acquired, token, err = r.tryLock(ctx, key, value, options.expiry, options.fencing)
+		return acquired, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	lock := r.newLock(key, value, options.expiry)
+	lock.token = token
+	if options.watchdog {
+		lock.startWatchdog(options.watchdogInterval)
+	}
+	return lock, nil
+}
+
+// acquire calls try until it succeeds, retrying according to the options until ctx is done
+// or the attempts are exhausted. The release notifications of key are waited between attempts
+func (r *Redigo) acquire(ctx context.Context, key string, options *lockOptions, try func(ctx context.Context) (bool, error)) error {
+	return r.acquireKeys(ctx, []string{key}, options, try)
+}
+
+// acquireKeys is like acquire, the release notifications of any of keys are waited between attempts
+func (r *Redigo) acquireKeys(ctx context.Context, keys []string, options *lockOptions, try func(ctx context.Context) (bool, error)) error {
+	start := time.Now()
+	err := r.retryAcquire(ctx, keys, options, try)
+	r.metrics.record(time.Since(start), err)
+	return err
+}
+
+func (r *Redigo) retryAcquire(ctx context.Context, keys []string, options *lockOptions, try func(ctx context.Context) (bool, error)) error {
+	// 获取失败后订阅释放通知, 通知丢失时仍按退避时间轮询
+	var waiter *unlockWaiter
+	defer func() {
+		if waiter != nil {
+			waiter.close()
+		}
+	}()
+
+	var notify = options.notify
+	for attempt := 1; ; attempt++ {
+		acquired, err := try(ctx)
 		if err != nil {
-			return nil, err
+			if ctx.Err() != nil {
+				return fmt.Errorf("%w: %w", ErrLockAcquisitionFailed, ctx.Err())
+			}
+			return err
 		}
 		if acquired {
-			lock := &Lock{
-				key:      key,
-				value:    value,
-				expiry:   int64(expiry.Seconds()),
-				redigo:   r,
-				acquired: true,
+			return nil
+		}
+
+		if options.maxAttempts > 0 && attempt >= options.maxAttempts {
+			return ErrLockAcquisitionFailed
+		}
+		backoff, ok := options.retry.NextBackoff(attempt)
+		if !ok {
+			return ErrLockAcquisitionFailed
+		}
+
+		if notify && waiter == nil {
+			if waiter, err = r.subscribeUnlock(ctx, keys...); err == nil {
+				// 失败后到订阅生效前的释放收不到通知, 订阅后立即重试一次
+				continue
 			}
-			return lock.unlock, nil
+			notify = false
 		}
 
 		// Wait a bit before retrying
-		time.Sleep(100 * time.Millisecond)
+		if err = waiter.wait(ctx, backoff); err != nil {
+			return fmt.Errorf("%w: %w", ErrLockAcquisitionFailed, err)
+		}
 	}
 }
 
-// TryLock tries to acquire a distributed lock with a timeout
-// It returns an unlock function and an error if failed to acquire lock within timeout
-func (r *Redigo) TryLock(key string, expiry time.Duration, timeout time.Duration) (func() error, error) {
-	value, err := randomValue()
+// WithLock acquires the lock like LockContext, runs fn and releases the lock.
+// The lease is renewed by a watchdog while fn is running, and the context passed to fn
+// is cancelled with cause ErrLockLost if the lease is lost. ErrLockNotHeld is returned
+// if fn succeeded but the lock was not held anymore when releasing it
+func (r *Redigo) WithLock(ctx context.Context, key string, fn func(ctx context.Context) error, opts ...LockOption) error {
+	if options := parseLockOptions(opts...); !options.watchdog {
+		// 截断容量, 避免写入调用方切片的底层数组
+		opts = append(slices.Clip(opts), WithWatchdog(0))
+	}
+	lock, err := r.LockContext(ctx, key, opts...)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
-	defer cancel()
-
-	for {
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	go func() {
 		select {
+		case <-lock.Lost():
+			cancel(ErrLockLost)
 		case <-ctx.Done():
-			return nil, ErrLockAcquisitionFailed
-		default:
-			acquired, err := r.tryLock(key, value, expiry)
-			if err != nil {
-				return nil, err
-			}
-			if acquired {
-				lock := &Lock{
-					key:      key,
-					value:    value,
-					expiry:   int64(expiry.Seconds()),
-					redigo:   r,
-					acquired: true,
-				}
-				return lock.unlock, nil
-			}
-
-			// Wait a bit before retrying
-			time.Sleep(50 * time.Millisecond)
 		}
+	}()
+
+	err = fn(ctx)
+	cancel(nil)
+	if unlockErr := lock.Unlock(); err == nil {
+		err = unlockErr
 	}
+	return err
 }
 
-// tryLock attempts to acquire the lock once
-func (r *Redigo) tryLock(key, value string, expiry time.Duration) (bool, error) {
-	conn, err := r.getConn()
-	if err != nil {
-		return false, err
+func (r *Redigo) newLock(key, value string, expiry time.Duration) *Lock {
+	return &Lock{
+		key:      key,
+		value:    value,
+		expiry:   expiry,
+		redigo:   r,
+		acquired: true,
+		lost:     make(chan struct{}),
+	}
+}
+
+// tryLock attempts to acquire the lock once, it returns false if the key already existed and we couldn't set it.
+// With fencing the fencing counter is incremented as well and its new value is returned as the token
+func (r *Redigo) tryLock(ctx context.Context, key, value string, expiry time.Duration, fencing bool) (bool, int64, error) {
+	if fencing {
+		// SET NX PX and INCR of the fencing counter are executed atomically
+		token, err := redis.Int64(r.evalScriptContext(ctx, lockScript, key, fencingKey(key), value, expiry.Milliseconds()))
+		return token > 0, token, err
 	}
-	defer conn.Close()
 
-	// Using SET with NX and EX options to atomically set the key only if it doesn't exist
-	// and automatically expire after the specified time
-	result, err := conn.Do("SET", key, value, "NX", "EX", int64(expiry.Seconds()))
+	conn, err := r.getConnContext(ctx)
 	if err != nil {
-		return false, err
+		return false, 0, err
 	}
+	defer conn.Close()
 
-	// If result is nil, it means the key already existed and we couldn't set it
-	if result == nil {
-		return false, nil
+	// The lock is automatically expired after the specified time
+	_, err = redis.String(redis.DoContext(conn, ctx, "SET", key, value, "NX", "PX", expiry.Milliseconds()))
+	if err == redis.ErrNil {
+		return false, 0, nil
 	}
+	return err == nil, 0, err
+}
+
+// Key returns the key of the lock
+func (l *Lock) Key() string {
+	return l.key
+}
 
-	return true, nil
+// Value returns the random value identifying the owner of the lock
+func (l *Lock) Value() string {
+	return l.value
 }
 
-// unlock releases the distributed lock
-func (l *Lock) unlock() error {
+// Unlock releases the distributed lock and stops the watchdog
+func (l *Lock) Unlock() error {
+	l.locker.Lock()
+	defer l.locker.Unlock()
 	if !l.acquired {
 		return ErrLockNotHeld
 	}
+	l.stopWatchdog()
 
-	conn, err := l.redigo.getConn()
-	if err != nil {
-		return err
-	}
-	defer conn.Close()
-
-	// Lua script to check if the lock is still held by us and delete it atomically
-	script := `
-		if redis.call("GET", KEYS[1]) == ARGV[1] then
-			return redis.call("DEL", KEYS[1])
-		else
-			return 0
-		end
-	`
-
-	result, err := conn.Do("EVAL", script, 1, l.key, l.value)
+	result, err := l.redigo.evalScript(unlockScript, l.key, l.value, lockChannel(l.key))
 	if err != nil {
 		return err
 	}
@@ -151,3 +301,134 @@ func (l *Lock) unlock() error {
 
 	return nil
 }
+
+// Token returns the fencing token of the lock acquired WithFencing, it is greater than the token of every
+// previous holder of the lock key. Pass it to SetFenced so the writes of a holder whose lock expired are
+// rejected. It is 0 if the lock was acquired without WithFencing
+func (l *Lock) Token() int64 {
+	return l.token
+}
+
+// Extend resets the expiry of the lock to d from now, the watchdog renews with d afterward.
+// It returns ErrLockNotHeld if the lock has expired or is held by someone else
+func (l *Lock) Extend(d time.Duration) error {
+	l.locker.Lock()
+	defer l.locker.Unlock()
+	if !l.acquired {
+		return ErrLockNotHeld
+	}
+	if err := l.extend(d); err != nil {
+		return err
+	}
+	l.expiry = d
+	if l.reset != nil {
+		// 未指定续期间隔时按新的租期调整, 避免租期缩短后在两次续期之间过期
+		select {
+		case <-l.reset:
+		default:
+		}
+		l.reset <- max(d/3, time.Millisecond)
+	}
+	return nil
+}
+
+func (l *Lock) extend(d time.Duration) error {
+	result, err := l.redigo.evalScript(extendScript, l.key, l.value, d.Milliseconds())
+	if err != nil {
+		return err
+	}
+	if result == int64(0) {
+		return ErrLockNotHeld
+	}
+	return nil
+}
+
+// TTL returns the remaining time to live of the lock, ErrLockNotHeld if it is not held by us anymore
+func (l *Lock) TTL() (time.Duration, error) {
+	var ttl int64
+	if err := l.redigo.RunScript(pttlScript, &ttl, l.key, l.value); err != nil {
+		return 0, err
+	}
+	if ttl == -3 {
+		return 0, ErrLockNotHeld
+	}
+	return time.Duration(ttl) * time.Millisecond, nil
+}
+
+// IsHeld reports whether the lock is still held by us
+func (l *Lock) IsHeld() (bool, error) {
+	_, err := l.TTL()
+	if errors.Is(err, ErrLockNotHeld) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Lost returns a channel closed when the watchdog fails to renew the lease,
+// the lock is not held anymore after it is closed
+func (l *Lock) Lost() <-chan struct{} {
+	return l.lost
+}
+
+// startWatchdog renews the lease every interval until Unlock. If interval is not positive,
+// a third of the expiry is used and the interval follows the expiry set by Extend
+func (l *Lock) startWatchdog(interval time.Duration) {
+	if interval <= 0 {
+		interval = l.expiry / 3
+		l.reset = make(chan time.Duration, 1)
+	}
+	l.stop = make(chan struct{})
+	go l.watchdog(interval, l.stop, l.reset)
+}
+
+func (l *Lock) stopWatchdog() {
+	if l.stop != nil {
+		close(l.stop)
+		l.stop = nil
+	}
+}
+
+func (l *Lock) watchdog(interval time.Duration, stop chan struct{}, reset chan time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var renewed = time.Now()
+	for {
+		select {
+		case <-stop:
+			return
+		case interval = <-reset:
+			// Extend刚续期过, 按新的间隔等待下一次续期
+			ticker.Reset(interval)
+			renewed = time.Now()
+			continue
+		case <-ticker.C:
+		}
+
+		l.locker.Lock()
+		select {
+		case <-stop:
+			l.locker.Unlock()
+			return
+		default:
+		}
+		err := l.extend(l.expiry)
+		expiry := l.expiry
+		l.locker.Unlock()
+
+		switch {
+		case err == nil:
+			renewed = time.Now()
+		case errors.Is(err, ErrLockNotHeld) || time.Since(renewed) >= expiry:
+			// 锁已被释放或续期失败时间超过租期, 视为丢失
+			l.markLost()
+			return
+		}
+	}
+}
+
+func (l *Lock) markLost() {
+	l.lostOnce.Do(func() {
+		close(l.lost)
+	})
+}
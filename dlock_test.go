@@ -1,6 +1,8 @@
 package redigo
 
 import (
+	"context"
+	"errors"
 	"testing"
 	"time"
 )
@@ -75,3 +77,258 @@ func TestRedigo_TryLockTimeout(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestRedigo_Obtain(t *testing.T) {
+	redigo := NewRedigo(opts...)
+
+	lock, err := redigo.Obtain(testLockKey, 10*time.Second, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = lock.Extend(time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	ttl, err := lock.TTL()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ttl <= 10*time.Second {
+		t.Fatalf("expected TTL greater than 10s after extend, got %v", ttl)
+	}
+	held, err := lock.IsHeld()
+	if err != nil || !held {
+		t.Fatalf("IsHeld returned %v %v", held, err)
+	}
+
+	if err = lock.Unlock(); err != nil {
+		t.Fatal(err)
+	}
+	if held, _ = lock.IsHeld(); held {
+		t.Fatal("lock still held after unlock")
+	}
+	if err = lock.Extend(time.Minute); err == nil {
+		t.Fatal("expected ErrLockNotHeld when extending a released lock")
+	}
+}
+
+func TestRedigo_Watchdog(t *testing.T) {
+	redigo := NewRedigo(opts...)
+
+	lock, err := redigo.Obtain(testLockKey, 300*time.Millisecond, time.Second, WithWatchdog(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 超过租期后仍被看门狗续期
+	time.Sleep(time.Second)
+	held, err := lock.IsHeld()
+	if err != nil || !held {
+		t.Fatalf("lock lost while the watchdog is running: %v %v", held, err)
+	}
+
+	// 锁被外部删除后看门狗报告丢失
+	if _, err = redigo.Del(testLockKey); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-lock.Lost():
+	case <-time.After(time.Second):
+		t.Fatal("lease loss not reported")
+	}
+	if err = lock.Unlock(); err == nil {
+		t.Fatal("expected ErrLockNotHeld when unlocking a lost lock")
+	}
+}
+
+func TestRedigo_LockContext(t *testing.T) {
+	redigo := NewRedigo(opts...)
+
+	lock, err := redigo.LockContext(context.Background(), testLockKey, WithLockExpiry(10*time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lock.Unlock()
+
+	_, err = redigo.LockContext(context.Background(), testLockKey, WithRetryStrategy(NoRetry()))
+	if !errors.Is(err, ErrLockAcquisitionFailed) {
+		t.Fatalf("expected ErrLockAcquisitionFailed without retry, got %v", err)
+	}
+	_, err = redigo.LockContext(context.Background(), testLockKey,
+		WithRetryStrategy(LinearBackoff(10*time.Millisecond, 50*time.Millisecond)), WithMaxAttempts(3))
+	if !errors.Is(err, ErrLockAcquisitionFailed) {
+		t.Fatalf("expected ErrLockAcquisitionFailed after max attempts, got %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	_, err = redigo.LockContext(ctx, testLockKey)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestRedigo_WithLock(t *testing.T) {
+	redigo := NewRedigo(opts...)
+
+	err := redigo.WithLock(context.Background(), testLockKey, func(ctx context.Context) error {
+		// 锁被外部删除后ctx被取消
+		if _, err := redigo.Del(testLockKey); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			if !errors.Is(context.Cause(ctx), ErrLockLost) {
+				t.Errorf("unexpected cancel cause %v", context.Cause(ctx))
+			}
+			return nil
+		case <-time.After(2 * time.Second):
+			return errors.New("lease loss not reported")
+		}
+	}, WithLockExpiry(300*time.Millisecond))
+	if !errors.Is(err, ErrLockNotHeld) {
+		t.Fatalf("expected ErrLockNotHeld after losing the lease, got %v", err)
+	}
+}
+
+func TestRedigo_LockNotify(t *testing.T) {
+	redigo := NewRedigo(opts...)
+
+	lock, err := redigo.LockContext(context.Background(), testLockKey, WithLockExpiry(10*time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		time.Sleep(200 * time.Millisecond)
+		_ = lock.Unlock()
+	}()
+
+	// 轮询间隔远大于持有时间, 只有收到释放通知才能很快获取到锁
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	lock2, err := redigo.LockContext(ctx, testLockKey, WithRetryStrategy(FixedRetry(10*time.Second)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lock2.Unlock()
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("waiter was not notified, acquired after %v", elapsed)
+	}
+}
+
+func TestRedigo_LockNotifySharedConn(t *testing.T) {
+	// 等待者共享一个订阅连接, 等待者多于连接池大小时释放锁仍能取得连接
+	redigo := NewRedigo(append(opts, WithMaxIdle(2), WithMaxActive(2))...)
+
+	lock, err := redigo.LockContext(context.Background(), testLockKey, WithLockExpiry(10*time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const waiters = 5
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	errs := make(chan error, waiters)
+	for i := 0; i < waiters; i++ {
+		go func() {
+			l, err := redigo.LockContext(ctx, testLockKey, WithLockExpiry(10*time.Second),
+				WithRetryStrategy(FixedRetry(10*time.Second)))
+			if err == nil {
+				err = l.Unlock()
+			}
+			errs <- err
+		}()
+	}
+
+	time.Sleep(200 * time.Millisecond)
+	if err = lock.Unlock(); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < waiters; i++ {
+		if err = <-errs; err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestRedigo_FencingToken(t *testing.T) {
+	redigo := NewRedigo(opts...)
+	const dataKey = "test_fenced_data_key"
+	_, _ = redigo.Del(testLockKey, dataKey, fencedTokenKey(dataKey), fencingKey(testLockKey))
+
+	// 默认不发放令牌, 也不创建计数器
+	plain, err := redigo.LockContext(context.Background(), testLockKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = plain.Unlock()
+	if n, _ := redigo.Exists(fencingKey(testLockKey)); plain.Token() != 0 || n != 0 {
+		t.Fatalf("unexpected fencing token %d without WithFencing", plain.Token())
+	}
+
+	first, err := redigo.LockContext(context.Background(), testLockKey, WithLockExpiry(200*time.Millisecond), WithFencing())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 第一个持有者暂停导致锁过期, 第二个持有者获得更大的令牌
+	time.Sleep(300 * time.Millisecond)
+	second, err := redigo.LockContext(context.Background(), testLockKey, WithLockExpiry(5*time.Second), WithFencing())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer second.Unlock()
+	if second.Token() <= first.Token() {
+		t.Fatalf("expected token greater than %d, got %d", first.Token(), second.Token())
+	}
+
+	if err = redigo.SetFenced(dataKey, "second", second.Token()); err != nil {
+		t.Fatal(err)
+	}
+	if err = redigo.SetFenced(dataKey, "first", first.Token()); !errors.Is(err, ErrStaleFencingToken) {
+		t.Fatalf("expected ErrStaleFencingToken, got %v", err)
+	}
+	var value string
+	if err = redigo.Get(dataKey, &value); err != nil || value != "second" {
+		t.Fatalf("expected second, got %q (%v)", value, err)
+	}
+}
+
+func TestRedigo_WatchdogFollowsExtend(t *testing.T) {
+	redigo := NewRedigo(opts...)
+	_, _ = redigo.Del(testLockKey)
+
+	// 缩短租期后看门狗按新租期的三分之一续期, 锁不会在两次续期之间过期
+	lock, err := redigo.Obtain(testLockKey, 3*time.Second, 0, WithWatchdog(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lock.Unlock()
+	if err = lock.Extend(300 * time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-lock.Lost():
+		t.Fatal("lock lost after Extend")
+	case <-time.After(1200 * time.Millisecond):
+	}
+	if held, err := lock.IsHeld(); err != nil || !held {
+		t.Fatalf("expected the lock to be held, got %v (%v)", held, err)
+	}
+}
+
+func TestRedigo_WithLockKeepsOptions(t *testing.T) {
+	redigo := NewRedigo(opts...)
+	_, _ = redigo.Del(testLockKey)
+
+	// 调用方切片的剩余容量不会被写入
+	lockOpts := make([]LockOption, 1, 4)
+	lockOpts[0] = WithLockExpiry(5 * time.Second)
+	err := redigo.WithLock(context.Background(), testLockKey, func(ctx context.Context) error {
+		return nil
+	}, lockOpts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lockOpts[:2][1] != nil {
+		t.Fatal("WithLock wrote into the spare capacity of the options")
+	}
+}
@@ -0,0 +1,202 @@
+package redigo
+
+import (
+	"context"
+	"github.com/gomodule/redigo/redis"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// leaderValueSeparator separates the node ID from the random part of the stored leader value
+	leaderValueSeparator = "#"
+)
+
+// LeaderElector elects a single leader among the nodes campaigning for the same key. The leader holds
+// the key as a lock with a lease renewed every retry period, and steps down if it could not renew
+// within the renew deadline so it stops leading before its lease expires even during a network partition
+type LeaderElector struct {
+	key     string
+	nodeID  string
+	redigo  *Redigo
+	options *electorOptions
+
+	locker  sync.Mutex
+	running bool
+	leading atomic.Bool
+	lock    *Lock
+}
+
+// LeaderElector returns the elector of key for the node nodeID, the node ID is stored with the lease
+// so every node can tell who the leader is
+func (r *Redigo) LeaderElector(key, nodeID string, opts ...ElectorOption) *LeaderElector {
+	return &LeaderElector{
+		key:     key,
+		nodeID:  nodeID,
+		redigo:  r,
+		options: parseElectorOptions(opts...),
+	}
+}
+
+// Key returns the key of the election
+func (e *LeaderElector) Key() string {
+	return e.key
+}
+
+// NodeID returns the node ID of the elector
+func (e *LeaderElector) NodeID() string {
+	return e.nodeID
+}
+
+// IsLeader reports whether this node is currently leading
+func (e *LeaderElector) IsLeader() bool {
+	return e.leading.Load()
+}
+
+// Token returns the fencing token of the current leadership, 0 if this node is not leading
+func (e *LeaderElector) Token() int64 {
+	e.locker.Lock()
+	defer e.locker.Unlock()
+	if e.lock == nil || !e.IsLeader() {
+		return 0
+	}
+	return e.lock.Token()
+}
+
+// Leader returns the node ID of the current leader, redis.ErrNil if there is none
+func (e *LeaderElector) Leader() (string, error) {
+	conn, err := e.redigo.getConn()
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+
+	value, err := redis.String(conn.Do("GET", e.key))
+	if err != nil {
+		return "", err
+	}
+	if i := strings.LastIndex(value, leaderValueSeparator); i >= 0 {
+		return value[:i], nil
+	}
+	return value, nil
+}
+
+// Run campaigns for the leadership until ctx is done. When elected, OnStartedLeading is run with a
+// context cancelled when the leadership is lost, and the lease is renewed until it cannot be renewed
+// within the renew deadline, then OnStoppedLeading is called and the node campaigns again.
+// When ctx is done the lease is released so another node takes over immediately, Run returns ctx.Err()
+func (e *LeaderElector) Run(ctx context.Context) error {
+	e.locker.Lock()
+	if e.running {
+		e.locker.Unlock()
+		return ErrElectorRunning
+	}
+	e.running = true
+	e.locker.Unlock()
+	defer func() {
+		e.locker.Lock()
+		e.running = false
+		e.locker.Unlock()
+	}()
+
+	for {
+		lock, err := e.campaign(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+		e.lead(ctx, lock)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+}
+
+// campaign 按重试周期尝试获取租约, 租约释放时立即重试
+func (e *LeaderElector) campaign(ctx context.Context) (*Lock, error) {
+	random, err := randomValue()
+	if err != nil {
+		return nil, err
+	}
+	value := e.nodeID + leaderValueSeparator + random
+	options := &lockOptions{
+		retry:   FixedRetry(e.options.retryPeriod),
+		notify:  true,
+		fencing: true,
+	}
+
+	var token int64
+	err = e.redigo.acquire(ctx, e.key, options, func(ctx context.Context) (bool, error) {
+		var acquired bool
+		acquired, token, err = e.redigo.tryLock(ctx, e.key, value, e.options.leaseDuration, options.fencing)
+		if err != nil && ctx.Err() == nil {
+			// 网络错误时继续参选
+			return false, nil
+		}
+		return acquired, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	lock := e.redigo.newLock(e.key, value, e.options.leaseDuration)
+	lock.token = token
+	return lock, nil
+}
+
+// lead 持续续期直到失去租约或ctx结束, 结束时回调OnStoppedLeading
+func (e *LeaderElector) lead(ctx context.Context, lock *Lock) {
+	e.locker.Lock()
+	e.lock = lock
+	e.locker.Unlock()
+	e.leading.Store(true)
+
+	leaderCtx, cancel := context.WithCancel(ctx)
+	if fn := e.options.onStartedLeading; fn != nil {
+		go fn(leaderCtx)
+	}
+
+	e.renew(ctx, lock)
+
+	e.leading.Store(false)
+	cancel()
+	if ctx.Err() != nil {
+		// 退出时主动让出领导权
+		_ = lock.Unlock()
+	}
+	if fn := e.options.onStoppedLeading; fn != nil {
+		fn()
+	}
+}
+
+// renew 每个重试周期续期一次, 在续期截止时间内未能续期或租约被他人持有时返回
+func (e *LeaderElector) renew(ctx context.Context, lock *Lock) {
+	ticker := time.NewTicker(e.options.retryPeriod)
+	defer ticker.Stop()
+
+	var renewed = time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		deadline := renewed.Add(e.options.renewDeadline)
+		start := time.Now()
+		renewCtx, cancel := context.WithDeadline(ctx, deadline)
+		result, err := e.redigo.evalScriptContext(renewCtx, extendScript, lock.key, lock.value, e.options.leaseDuration.Milliseconds())
+		cancel()
+		switch {
+		case err == nil && result == int64(0):
+			return
+		case err == nil:
+			renewed = start
+		case !time.Now().Before(deadline):
+			return
+		}
+	}
+}
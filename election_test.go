@@ -0,0 +1,79 @@
+package redigo
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+const (
+	testElectionKey = "test_election_key"
+)
+
+func TestRedigo_LeaderElector(t *testing.T) {
+	redigo := NewRedigo(opts...)
+	_, _ = redigo.Del(testElectionKey)
+
+	started := make(chan string, 2)
+	stopped := make(chan string, 2)
+	newElector := func(nodeID string) *LeaderElector {
+		return redigo.LeaderElector(testElectionKey, nodeID,
+			WithLeaseDuration(time.Second),
+			WithRenewDeadline(600*time.Millisecond),
+			WithRetryPeriod(100*time.Millisecond),
+			WithOnStartedLeading(func(ctx context.Context) {
+				started <- nodeID
+			}),
+			WithOnStoppedLeading(func() {
+				stopped <- nodeID
+			}),
+		)
+	}
+
+	e1 := newElector("node-1")
+	ctx1, cancel1 := context.WithCancel(context.Background())
+	done1 := make(chan error, 1)
+	go func() {
+		done1 <- e1.Run(ctx1)
+	}()
+	if nodeID := <-started; nodeID != "node-1" {
+		t.Fatalf("expected node-1 leading, got %s", nodeID)
+	}
+
+	e2 := newElector("node-2")
+	ctx2, cancel2 := context.WithCancel(context.Background())
+	defer cancel2()
+	go func() {
+		_ = e2.Run(ctx2)
+	}()
+	time.Sleep(1500 * time.Millisecond)
+	if !e1.IsLeader() || e2.IsLeader() {
+		t.Fatalf("expected node-1 to stay leader, leaders: %v %v", e1.IsLeader(), e2.IsLeader())
+	}
+	if leader, err := e2.Leader(); err != nil || leader != "node-1" {
+		t.Fatalf("expected leader node-1, got %q (%v)", leader, err)
+	}
+	if e1.Token() == 0 {
+		t.Fatal("expected fencing token of the leader")
+	}
+
+	// 退出时让出领导权, 另一节点立即接任
+	cancel1()
+	if err := <-done1; err != context.Canceled {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if nodeID := <-stopped; nodeID != "node-1" {
+		t.Fatalf("expected node-1 stopped, got %s", nodeID)
+	}
+	select {
+	case nodeID := <-started:
+		if nodeID != "node-2" {
+			t.Fatalf("expected node-2 leading, got %s", nodeID)
+		}
+	case <-time.After(500 * time.Millisecond):
+		t.Fatal("node-2 did not take over after node-1 resigned")
+	}
+	if leader, err := e1.Leader(); err != nil || leader != "node-2" {
+		t.Fatalf("expected leader node-2, got %q (%v)", leader, err)
+	}
+}
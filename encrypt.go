@@ -0,0 +1,401 @@
+package redigo
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"github.com/gomodule/redigo/redis"
+	"sync"
+)
+
+const (
+	encryptionRandom        byte = 0 // random nonce
+	encryptionDeterministic byte = 1 // nonce derived from the plaintext
+)
+
+// encryptedMagic starts the header of an encrypted value, followed by the mode,
+// the length of the key ID and the key ID. The nonce and the ciphertext come next
+var encryptedMagic = []byte{0x00, 'r', 'e'}
+
+// reencryptScript replaces the value of KEYS[1] by ARGV[2] keeping its expiry if it is still ARGV[1]
+var reencryptScript = NewScript(1, `
+	if redis.call("GET", KEYS[1]) == ARGV[1] then
+		redis.call("SET", KEYS[1], ARGV[2], "KEEPTTL")
+		return 1
+	end
+	return 0
+`)
+
+// reencryptFieldScript replaces the value of the field ARGV[1] of the hash KEYS[1] by ARGV[3] if it is still ARGV[2]
+var reencryptFieldScript = NewScript(1, `
+	if redis.call("HGET", KEYS[1], ARGV[1]) == ARGV[2] then
+		redis.call("HSET", KEYS[1], ARGV[1], ARGV[3])
+		return 1
+	end
+	return 0
+`)
+
+// reencryptMemberScript replaces the member ARGV[1] of the set KEYS[1] by ARGV[2] if it is still in the set
+var reencryptMemberScript = NewScript(1, `
+	if redis.call("SREM", KEYS[1], ARGV[1]) == 1 then
+		redis.call("SADD", KEYS[1], ARGV[2])
+		return 1
+	end
+	return 0
+`)
+
+// encryptionKey 加密密钥及派生的确定性nonce密钥
+type encryptionKey struct {
+	aead     cipher.AEAD
+	nonceKey []byte
+}
+
+// Keyring holds the AES keys of the envelope encryption enabled by WithEncryption. Values are encrypted
+// with the primary key and carry its ID, so they are decrypted with any key of the ring and the primary
+// key can be rotated while the values encrypted with the previous keys remain readable
+type Keyring struct {
+	locker  sync.RWMutex
+	primary string
+	keys    map[string]*encryptionKey
+}
+
+// NewKeyring returns an empty keyring
+func NewKeyring() *Keyring {
+	return &Keyring{
+		keys: make(map[string]*encryptionKey),
+	}
+}
+
+// AddKey adds the AES-128, AES-192 or AES-256 key (16, 24 or 32 bytes) identified by id,
+// the first key added becomes the primary key
+func (k *Keyring) AddKey(id string, key []byte) error {
+	if id == "" || len(id) > 255 {
+		return fmt.Errorf("%w: invalid key ID %q", ErrEncryptionKey, id)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrEncryptionKey, err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return err
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte("redigo deterministic nonce"))
+
+	k.locker.Lock()
+	defer k.locker.Unlock()
+	k.keys[id] = &encryptionKey{aead: aead, nonceKey: mac.Sum(nil)}
+	if k.primary == "" {
+		k.primary = id
+	}
+	return nil
+}
+
+// SetPrimary sets the key encrypting the new values, the other keys are only used to decrypt
+func (k *Keyring) SetPrimary(id string) error {
+	k.locker.Lock()
+	defer k.locker.Unlock()
+	if _, ok := k.keys[id]; !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownEncryptionKey, id)
+	}
+	k.primary = id
+	return nil
+}
+
+// Primary returns the ID of the primary key
+func (k *Keyring) Primary() string {
+	k.locker.RLock()
+	defer k.locker.RUnlock()
+	return k.primary
+}
+
+// RemoveKey removes a retired key, the values still encrypted with it cannot be read anymore
+func (k *Keyring) RemoveKey(id string) {
+	k.locker.Lock()
+	defer k.locker.Unlock()
+	delete(k.keys, id)
+	if k.primary == id {
+		k.primary = ""
+	}
+}
+
+func (k *Keyring) key(id string) (*encryptionKey, error) {
+	k.locker.RLock()
+	defer k.locker.RUnlock()
+	key, ok := k.keys[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownEncryptionKey, id)
+	}
+	return key, nil
+}
+
+// encrypt 使用主密钥加密, 确定性模式下相同明文得到相同密文
+func (k *Keyring) encrypt(data []byte, mode byte) ([]byte, error) {
+	id := k.Primary()
+	key, err := k.key(id)
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, 0, len(encryptedMagic)+2+len(id))
+	header = append(header, encryptedMagic...)
+	header = append(header, mode, byte(len(id)))
+	header = append(header, id...)
+
+	nonce := make([]byte, key.aead.NonceSize())
+	if mode == encryptionDeterministic {
+		mac := hmac.New(sha256.New, key.nonceKey)
+		mac.Write(data)
+		copy(nonce, mac.Sum(nil))
+	} else if _, err = rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, 0, len(header)+len(nonce)+len(data)+key.aead.Overhead())
+	out = append(out, header...)
+	out = append(out, nonce...)
+	// 头部作为附加数据, 防止篡改模式和密钥ID
+	return key.aead.Seal(out, nonce, data, header), nil
+}
+
+// parseEnvelope 解析加密数据的头部, 返回模式、密钥ID和头部长度, 非加密数据返回false
+func parseEnvelope(data []byte) (mode byte, id string, n int, ok bool) {
+	n = len(encryptedMagic)
+	if len(data) < n+2 || !bytes.Equal(data[:n], encryptedMagic) {
+		return 0, "", 0, false
+	}
+	mode, size := data[n], int(data[n+1])
+	n += 2
+	if len(data) < n+size {
+		return 0, "", 0, false
+	}
+	return mode, string(data[n : n+size]), n + size, true
+}
+
+// decrypt 解密加密数据, 非加密数据原样返回
+func (k *Keyring) decrypt(data []byte) ([]byte, error) {
+	_, id, n, ok := parseEnvelope(data)
+	if !ok {
+		return data, nil
+	}
+	if k == nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownEncryptionKey, id)
+	}
+	key, err := k.key(id)
+	if err != nil {
+		return nil, err
+	}
+	size := key.aead.NonceSize()
+	if len(data) < n+size {
+		return nil, fmt.Errorf("%w: truncated ciphertext", ErrDecryptionFailed)
+	}
+	plain, err := key.aead.Open(nil, data[n:n+size], data[n+size:], data[:n])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDecryptionFailed, err)
+	}
+	return plain, nil
+}
+
+// encrypt 启用加密时加密数据
+func (r *Redigo) encrypt(data []byte) ([]byte, error) {
+	if r.options.keyring == nil {
+		return data, nil
+	}
+	var mode = encryptionRandom
+	if r.options.deterministic {
+		mode = encryptionDeterministic
+	}
+	return r.options.keyring.encrypt(data, mode)
+}
+
+// WithDeterministicEncryption returns a shallow copy of the client encrypting the values
+// deterministically: equal values give equal ciphertexts so they can be used as set members
+// or matched by LRem, LPos... at the cost of revealing which values are equal
+func (r *Redigo) WithDeterministicEncryption() *Redigo {
+	options := *r.options
+	options.deterministic = true
+	cp := *r
+	cp.options = &options
+	return &cp
+}
+
+// ReencryptKeys re-encrypts with the primary key the values of the string keys, the fields of the hash keys
+// and the members of the set keys matching pattern which were encrypted with another key, keeping their
+// mode and expiry. The keys are processed batch by batch as SCAN returns them and each value is replaced
+// only if it did not change in the meantime. The list elements are left as is. The values which cannot be
+// decrypted, such as the values encrypted with a key missing from the keyring, are skipped and reported in
+// the returned error once the walk is done. It returns the number of re-encrypted values
+func (r *Redigo) ReencryptKeys(ctx context.Context, pattern string) (int64, error) {
+	keyring := r.options.keyring
+	if keyring == nil {
+		return 0, fmt.Errorf("%w: encryption is not enabled", ErrEncryptionKey)
+	}
+	if _, err := keyring.key(keyring.Primary()); err != nil {
+		return 0, err
+	}
+
+	// 遍历、读取和替换都使用同一个连接
+	conn, err := r.getConnContext(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	re := &reencryption{redigo: r, ctx: ctx, conn: conn, keyring: keyring}
+	err = scanKeys(ctx, conn, pattern, "string", re.stringKeys)
+	if err == nil {
+		err = scanKeys(ctx, conn, pattern, "hash", func(keys []string) error {
+			for _, key := range keys {
+				if err := re.hash(key); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+	if err == nil {
+		err = scanKeys(ctx, conn, pattern, "set", func(keys []string) error {
+			for _, key := range keys {
+				if err := re.set(key); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+	return re.n, errors.Join(append([]error{err}, re.skipped...)...)
+}
+
+// reencryption 保存一次ReencryptKeys的连接、重新加密的值数和跳过的值
+type reencryption struct {
+	redigo  *Redigo
+	ctx     context.Context
+	conn    redis.Conn
+	keyring *Keyring
+	n       int64
+	skipped []error
+}
+
+// reencrypt 使用主密钥重新加密旧密钥加密的数据, 未加密、已使用主密钥或无法解密的数据返回false, 无法解密的数据记录为跳过
+func (re *reencryption) reencrypt(data []byte, name string) ([]byte, bool) {
+	mode, id, _, ok := parseEnvelope(data)
+	if !ok || id == re.keyring.Primary() {
+		return nil, false
+	}
+	plain, err := re.keyring.decrypt(data)
+	if err == nil {
+		data, err = re.keyring.encrypt(plain, mode)
+	}
+	if err != nil {
+		re.skipped = append(re.skipped, fmt.Errorf("%s: %w", name, err))
+		return nil, false
+	}
+	return data, true
+}
+
+// eval 执行替换脚本, 替换成功时计数
+func (re *reencryption) eval(s *Script, keysAndArgs ...any) error {
+	result, err := re.redigo.evalScriptConn(re.ctx, re.conn, s, keysAndArgs...)
+	if err != nil {
+		return err
+	}
+	if result == int64(1) {
+		re.n++
+	}
+	return nil
+}
+
+// stringKeys 重新加密一批字符串key的值
+func (re *reencryption) stringKeys(keys []string) error {
+	values, err := getValues(re.ctx, re.conn, keys)
+	if err != nil {
+		return err
+	}
+	for i, key := range keys {
+		data, _ := values[i].([]byte)
+		sealed, ok := re.reencrypt(data, key)
+		if !ok {
+			continue
+		}
+		if err = re.eval(reencryptScript, key, data, sealed); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hash 逐批重新加密哈希中使用旧密钥加密的字段
+func (re *reencryption) hash(key string) error {
+	for cursor := int64(0); ; {
+		values, err := redis.Values(redis.DoContext(re.conn, re.ctx, "HSCAN", key, cursor, "COUNT", 100))
+		if err != nil {
+			return err
+		}
+		var pairs [][]byte
+		if _, err = redis.Scan(values, &cursor, &pairs); err != nil {
+			return err
+		}
+		for i := 0; i+1 < len(pairs); i += 2 {
+			field, data := pairs[i], pairs[i+1]
+			sealed, ok := re.reencrypt(data, fmt.Sprintf("%s %s", key, field))
+			if !ok {
+				continue
+			}
+			if err = re.eval(reencryptFieldScript, key, field, data, sealed); err != nil {
+				return err
+			}
+		}
+		if cursor == 0 {
+			return nil
+		}
+	}
+}
+
+// set 逐批重新加密集合中使用旧密钥加密的成员
+func (re *reencryption) set(key string) error {
+	for cursor := int64(0); ; {
+		values, err := redis.Values(redis.DoContext(re.conn, re.ctx, "SSCAN", key, cursor, "COUNT", 100))
+		if err != nil {
+			return err
+		}
+		var members [][]byte
+		if _, err = redis.Scan(values, &cursor, &members); err != nil {
+			return err
+		}
+		for _, data := range members {
+			sealed, ok := re.reencrypt(data, key)
+			if !ok {
+				continue
+			}
+			if err = re.eval(reencryptMemberScript, key, data, sealed); err != nil {
+				return err
+			}
+		}
+		if cursor == 0 {
+			return nil
+		}
+	}
+}
+
+// getValues 一次读取多个key的原始值, 不存在的key为nil
+func getValues(ctx context.Context, conn redis.Conn, keys []string) ([]any, error) {
+	var args = make([]any, 0, len(keys))
+	for _, key := range keys {
+		args = append(args, key)
+	}
+	values, err := redis.Values(redis.DoContext(conn, ctx, "MGET", args...))
+	if err != nil {
+		return nil, err
+	}
+	if len(values) != len(keys) {
+		return nil, fmt.Errorf("%w: MGET returned %d values for %d keys", ErrInvalidResponse, len(values), len(keys))
+	}
+	return values, nil
+}
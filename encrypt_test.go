@@ -0,0 +1,244 @@
+package redigo
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/rand"
+	"errors"
+	"github.com/gomodule/redigo/redis"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	redigoEncryptKey = "redigoEncrypt:user"
+)
+
+func newTestKey(t *testing.T) []byte {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func TestRedigo_Encryption(t *testing.T) {
+	keyring := NewKeyring()
+	if err := keyring.AddKey("k1", newTestKey(t)); err != nil {
+		t.Fatal(err)
+	}
+	if err := keyring.AddKey("bad", []byte("short")); !errors.Is(err, ErrEncryptionKey) {
+		t.Fatalf("expected ErrEncryptionKey, got %v", err)
+	}
+	redigo := NewRedigo(append(opts, WithEncryption(keyring), WithCompression(GzipCompressor(gzip.DefaultCompression), 64))...)
+
+	user := User{ID: 1, Name: strings.Repeat("lory", 50)}
+	if err := redigo.Set(redigoEncryptKey, &user); err != nil {
+		t.Fatal(err)
+	}
+	raw, err := redis.Bytes(redigo.Do("GET", redigoEncryptKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(raw, encryptedMagic) || bytes.Contains(raw, []byte("lory")) {
+		t.Fatalf("expected encrypted value, got %q", raw)
+	}
+	var got User
+	if err = redigo.Get(redigoEncryptKey, &got); err != nil || got != user {
+		t.Fatalf("expected %+v, got %+v (%v)", user, got, err)
+	}
+
+	// 未配置密钥的客户端无法读取
+	if err = NewRedigo(opts...).Get(redigoEncryptKey, &got); !errors.Is(err, ErrUnknownEncryptionKey) {
+		t.Fatalf("expected ErrUnknownEncryptionKey, got %v", err)
+	}
+
+	// 随机模式每次密文不同, 确定性模式相同
+	a, _ := redigo.encodeValue("member")
+	b, _ := redigo.encodeValue("member")
+	if bytes.Equal(a.([]byte), b.([]byte)) {
+		t.Fatal("expected different ciphertexts")
+	}
+	deterministic := redigo.WithDeterministicEncryption()
+	a, _ = deterministic.encodeValue("member")
+	b, _ = deterministic.encodeValue("member")
+	if !bytes.Equal(a.([]byte), b.([]byte)) {
+		t.Fatal("expected equal ciphertexts")
+	}
+}
+
+func TestRedigo_EncryptionPipeline(t *testing.T) {
+	keyring := NewKeyring()
+	if err := keyring.AddKey("k1", newTestKey(t)); err != nil {
+		t.Fatal(err)
+	}
+	redigo := NewRedigo(append(opts, WithEncryption(keyring))...)
+	if err := redigo.Set(redigoEncryptKey, "secret"); err != nil {
+		t.Fatal(err)
+	}
+
+	// 管道和事务中的Get返回解密后的值
+	pipe, err := redigo.Pipeline()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pipe.Close()
+	get := pipe.Get(redigoEncryptKey)
+	if err = pipe.Exec(); err != nil {
+		t.Fatal(err)
+	}
+	if s, err := get.Result(); err != nil || s != "secret" {
+		t.Fatalf("expected secret, got %q (%v)", s, err)
+	}
+
+	tx, err := redigo.Tx()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Close()
+	get = tx.Get(redigoEncryptKey)
+	if err = tx.Exec(); err != nil {
+		t.Fatal(err)
+	}
+	if s, err := get.Result(); err != nil || s != "secret" {
+		t.Fatalf("expected secret, got %q (%v)", s, err)
+	}
+}
+
+func TestRedigo_ReencryptKeys(t *testing.T) {
+	keyring := NewKeyring()
+	if err := keyring.AddKey("k1", newTestKey(t)); err != nil {
+		t.Fatal(err)
+	}
+	redigo := NewRedigo(append(opts, WithEncryption(keyring))...)
+	hashKey := redigoEncryptKey + ":hash"
+	_, _ = redigo.Del(redigoEncryptKey+":int", redigoEncryptKey+":email", hashKey)
+	if err := redigo.Set(redigoEncryptKey, "secret", WithEX(60)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := redigo.HSet(hashKey, map[string]string{"a": "secret a", "b": "secret b"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// 轮换主密钥后旧值仍可读取, 重新加密后使用新密钥
+	if err := keyring.AddKey("k2", newTestKey(t)); err != nil {
+		t.Fatal(err)
+	}
+	if err := keyring.SetPrimary("k2"); err != nil {
+		t.Fatal(err)
+	}
+	var s string
+	if err := redigo.Get(redigoEncryptKey, &s); err != nil || s != "secret" {
+		t.Fatalf("expected secret, got %q (%v)", s, err)
+	}
+	n, err := redigo.ReencryptKeys(context.Background(), "redigoEncrypt:*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 3 {
+		t.Fatalf("expected 3 re-encrypted values, got %d", n)
+	}
+	keyring.RemoveKey("k1")
+	if err = redigo.Get(redigoEncryptKey, &s); err != nil || s != "secret" {
+		t.Fatalf("expected secret, got %q (%v)", s, err)
+	}
+	if err = redigo.HGet(hashKey, "b", &s); err != nil || s != "secret b" {
+		t.Fatalf("expected secret b, got %q (%v)", s, err)
+	}
+	if ttl, _ := redigo.TTL(redigoEncryptKey); ttl <= 0 {
+		t.Fatalf("expected the expiry to be kept, got %d", ttl)
+	}
+
+	// 连接池只有一个连接时同样可以完成
+	single := NewRedigo(append(opts, WithEncryption(keyring), WithMaxIdle(1), WithMaxActive(1))...)
+	if err = keyring.AddKey("k3", newTestKey(t)); err != nil {
+		t.Fatal(err)
+	}
+	if err = keyring.SetPrimary("k3"); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	if n, err = single.ReencryptKeys(ctx, "redigoEncrypt:*"); err != nil || n != 3 {
+		t.Fatalf("expected 3 re-encrypted values, got %d (%v)", n, err)
+	}
+}
+
+func TestRedigo_ReencryptKeysSkipsUnknownKey(t *testing.T) {
+	keyring := NewKeyring()
+	if err := keyring.AddKey("k1", newTestKey(t)); err != nil {
+		t.Fatal(err)
+	}
+	lost := NewKeyring()
+	if err := lost.AddKey("lost", newTestKey(t)); err != nil {
+		t.Fatal(err)
+	}
+	redigo := NewRedigo(append(opts, WithEncryption(keyring))...)
+	known, unknown := "redigoEncryptSkip:known", "redigoEncryptSkip:a-unknown"
+	defer redigo.Del(known, unknown)
+	if err := redigo.Set(known, "secret"); err != nil {
+		t.Fatal(err)
+	}
+	if err := NewRedigo(append(opts, WithEncryption(lost))...).Set(unknown, "lost secret"); err != nil {
+		t.Fatal(err)
+	}
+	if err := keyring.AddKey("k2", newTestKey(t)); err != nil {
+		t.Fatal(err)
+	}
+	if err := keyring.SetPrimary("k2"); err != nil {
+		t.Fatal(err)
+	}
+
+	// 未知密钥加密的值被跳过并报告, 其他值仍重新加密
+	n, err := redigo.ReencryptKeys(context.Background(), "redigoEncryptSkip:*")
+	if !errors.Is(err, ErrUnknownEncryptionKey) || !strings.Contains(err.Error(), unknown) {
+		t.Fatalf("expected ErrUnknownEncryptionKey for %s, got %v", unknown, err)
+	}
+	if n != 1 {
+		t.Fatalf("expected 1 re-encrypted value, got %d", n)
+	}
+	keyring.RemoveKey("k1")
+	var s string
+	if err = redigo.Get(known, &s); err != nil || s != "secret" {
+		t.Fatalf("expected secret, got %q (%v)", s, err)
+	}
+}
+
+type testEmail string
+
+func TestRedigo_EncryptBasicTypes(t *testing.T) {
+	keyring := NewKeyring()
+	if err := keyring.AddKey("k1", newTestKey(t)); err != nil {
+		t.Fatal(err)
+	}
+	redigo := NewRedigo(append(opts, WithEncryption(keyring))...)
+	intKey, emailKey := redigoEncryptKey+":int", redigoEncryptKey+":email"
+
+	// 整数和自定义字符串类型同样加密存储
+	if err := redigo.Set(intKey, int64(1234567)); err != nil {
+		t.Fatal(err)
+	}
+	if err := redigo.Set(emailKey, testEmail("lory@example.com")); err != nil {
+		t.Fatal(err)
+	}
+	for key, plain := range map[string]string{intKey: "1234567", emailKey: "lory@example.com"} {
+		raw, err := redis.Bytes(redigo.Do("GET", key))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.HasPrefix(raw, encryptedMagic) || bytes.Contains(raw, []byte(plain)) {
+			t.Fatalf("expected encrypted value of %s, got %q", key, raw)
+		}
+	}
+
+	var n int64
+	if err := redigo.Get(intKey, &n); err != nil || n != 1234567 {
+		t.Fatalf("expected 1234567, got %d (%v)", n, err)
+	}
+	var email testEmail
+	if err := redigo.Get(emailKey, &email); err != nil || email != "lory@example.com" {
+		t.Fatalf("expected lory@example.com, got %q (%v)", email, err)
+	}
+}
@@ -8,4 +8,19 @@ var (
 	ErrInvalidResponse       = errors.New("invalid response from server")
 	ErrLockAcquisitionFailed = errors.New("failed to acquire lock")
 	ErrLockNotHeld           = errors.New("lock not held by this instance")
+	ErrLockLost              = errors.New("lock lease lost")
+	ErrResultPending         = errors.New("result is not available before Exec")
+	ErrPipelineClosed        = errors.New("pipeline closed")
+	ErrTxAborted             = errors.New("transaction aborted: watched key changed")
+	ErrTxFailed              = errors.New("transaction failed: max retries exceeded")
+	ErrTxQueued              = errors.New("transaction commands already queued")
+	ErrCounterLimitExceeded  = errors.New("counter limit exceeded")
+	ErrInvalidPermits        = errors.New("invalid number of permits")
+	ErrStaleFencingToken     = errors.New("fencing token is older than the newest one seen")
+	ErrElectorRunning        = errors.New("leader elector is already running")
+	ErrUnknownCompressor     = errors.New("unknown compressor")
+	ErrEncryptionKey         = errors.New("invalid encryption key")
+	ErrUnknownEncryptionKey  = errors.New("unknown encryption key")
+	ErrDecryptionFailed      = errors.New("failed to decrypt value")
+	ErrNoLockKeys            = errors.New("no keys to lock")
 )
@@ -0,0 +1,129 @@
+package redigo
+
+import (
+	"context"
+	"time"
+)
+
+// fairCleanup removes the waiters whose queue entry timed out, KEYS[2] is the queue of waiters and
+// KEYS[3] the sorted set of their timeouts. It defines now, the server time in milliseconds
+const fairCleanup = `
+	local t = redis.call("TIME")
+	local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+	for _, waiter in ipairs(redis.call("ZRANGEBYSCORE", KEYS[3], "-inf", now)) do
+		redis.call("LREM", KEYS[2], 0, waiter)
+		redis.call("ZREM", KEYS[3], waiter)
+	end
+`
+
+// fairLockScript acquires the lock KEYS[1] for ARGV[1] if it was handed over to it, or if the lock is free
+// and it is the head of the queue (or the queue is empty). Otherwise ARGV[1] is enqueued, or its entry is
+// refreshed, with a timeout of ARGV[3] milliseconds. It returns 1 if the lock was acquired, 0 otherwise
+var fairLockScript = NewScript(3, fairCleanup+`
+	local acquired = redis.call("GET", KEYS[1]) == ARGV[1]
+	if not acquired and redis.call("EXISTS", KEYS[1]) == 0 then
+		local head = redis.call("LINDEX", KEYS[2], 0)
+		acquired = not head or head == ARGV[1]
+	end
+	if acquired then
+		redis.call("SET", KEYS[1], ARGV[1], "PX", ARGV[2])
+		redis.call("LREM", KEYS[2], 0, ARGV[1])
+		redis.call("ZREM", KEYS[3], ARGV[1])
+		return 1
+	end
+	if not redis.call("ZSCORE", KEYS[3], ARGV[1]) then
+		redis.call("RPUSH", KEYS[2], ARGV[1])
+	end
+	redis.call("ZADD", KEYS[3], now + tonumber(ARGV[3]), ARGV[1])
+	redis.call("PEXPIRE", KEYS[2], ARGV[3])
+	redis.call("PEXPIRE", KEYS[3], ARGV[3])
+	return 0
+`)
+
+// fairUnlockScript removes ARGV[1] from the queue and, if it holds the lock, hands the lock over to
+// the head of the queue for ARGV[2] milliseconds (the head extends it when it notices) or deletes it,
+// then publishes the key to the channel ARGV[3]. It returns 1 if ARGV[1] held the lock, 0 otherwise
+var fairUnlockScript = NewScript(3, fairCleanup+`
+	redis.call("LREM", KEYS[2], 0, ARGV[1])
+	redis.call("ZREM", KEYS[3], ARGV[1])
+	if redis.call("GET", KEYS[1]) ~= ARGV[1] then
+		return 0
+	end
+	local head = redis.call("LINDEX", KEYS[2], 0)
+	if head then
+		redis.call("SET", KEYS[1], head, "PX", ARGV[2])
+	else
+		redis.call("DEL", KEYS[1])
+	end
+	redis.call("PUBLISH", ARGV[3], KEYS[1])
+	return 1
+`)
+
+// FairLock is a distributed lock granted in FIFO order. Waiters enqueue themselves and the lock is
+// handed over to the head of the queue on release, so no waiter starves under contention.
+// It supports the Extend, TTL, IsHeld and watchdog of Lock
+type FairLock struct {
+	*Lock
+	waiterTimeout time.Duration
+}
+
+// LockFair acquires the fair lock key, waiting for its turn in the queue until ctx is done or
+// WithMaxAttempts is reached, in which case an error wrapping ErrLockAcquisitionFailed is returned.
+// The queue entry of a waiter expires after WithWaiterTimeout if it stops refreshing it
+func (r *Redigo) LockFair(ctx context.Context, key string, opts ...LockOption) (*FairLock, error) {
+	options := parseLockOptions(opts...)
+	value, err := newLockValue(options)
+	if err != nil {
+		return nil, err
+	}
+
+	queue, timeouts := fairLockKeys(key)
+	err = r.acquire(ctx, key, options, func(ctx context.Context) (bool, error) {
+		result, err := r.evalScriptContext(ctx, fairLockScript, key, queue, timeouts,
+			value, options.expiry.Milliseconds(), options.waiterTimeout.Milliseconds())
+		return result == int64(1), err
+	})
+	if err != nil {
+		// 放弃等待时离开队列, 若锁已移交给自己则继续移交给下一个等待者
+		_, _ = r.evalScript(fairUnlockScript, key, queue, timeouts,
+			value, options.waiterTimeout.Milliseconds(), lockChannel(key))
+		return nil, err
+	}
+
+	lock := &FairLock{
+		Lock:          r.newLock(key, value, options.expiry),
+		waiterTimeout: options.waiterTimeout,
+	}
+	if options.watchdog {
+		lock.startWatchdog(options.watchdogInterval)
+	}
+	return lock, nil
+}
+
+// fairLockKeys 返回等待队列和等待超时集合的key
+func fairLockKeys(key string) (queue, timeouts string) {
+	return key + ":queue", key + ":timeouts"
+}
+
+// Unlock releases the lock and hands it over to the next waiter, it returns ErrLockNotHeld
+// if the lock has expired or is held by someone else
+func (l *FairLock) Unlock() error {
+	l.locker.Lock()
+	defer l.locker.Unlock()
+	if !l.acquired {
+		return ErrLockNotHeld
+	}
+	l.stopWatchdog()
+
+	queue, timeouts := fairLockKeys(l.key)
+	result, err := l.redigo.evalScript(fairUnlockScript, l.key, queue, timeouts,
+		l.value, l.waiterTimeout.Milliseconds(), lockChannel(l.key))
+	if err != nil {
+		return err
+	}
+	l.acquired = false
+	if result == int64(0) {
+		return ErrLockNotHeld
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package redigo
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+const (
+	testFairLockKey = "test_fair_lock_key"
+)
+
+func TestRedigo_LockFair(t *testing.T) {
+	redigo := NewRedigo(opts...)
+	queue, timeouts := fairLockKeys(testFairLockKey)
+	_, _ = redigo.Del(testFairLockKey, queue, timeouts)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	holder, err := redigo.LockFair(ctx, testFairLockKey, WithLockExpiry(5*time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 等待者按入队顺序获取锁
+	order := make(chan int, 3)
+	for i := 1; i <= 3; i++ {
+		go func(i int) {
+			lock, err := redigo.LockFair(ctx, testFairLockKey, WithLockExpiry(5*time.Second))
+			if err != nil {
+				t.Error(err)
+				order <- 0
+				return
+			}
+			order <- i
+			time.Sleep(50 * time.Millisecond)
+			_ = lock.Unlock()
+		}(i)
+		time.Sleep(100 * time.Millisecond)
+	}
+	if n, _ := redigo.ListLen(queue); n != 3 {
+		t.Fatalf("expected 3 waiters, got %d", n)
+	}
+
+	if err = holder.Unlock(); err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i <= 3; i++ {
+		if got := <-order; got != i {
+			t.Fatalf("expected waiter %d, got %d", i, got)
+		}
+	}
+	if err = holder.Unlock(); !errors.Is(err, ErrLockNotHeld) {
+		t.Fatalf("expected ErrLockNotHeld, got %v", err)
+	}
+}
+
+func TestRedigo_LockFairAbandon(t *testing.T) {
+	redigo := NewRedigo(opts...)
+	queue, timeouts := fairLockKeys(testFairLockKey)
+	_, _ = redigo.Del(testFairLockKey, queue, timeouts)
+
+	holder, err := redigo.LockFair(context.Background(), testFairLockKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer holder.Unlock()
+
+	// 放弃等待的请求离开队列
+	_, err = redigo.LockFair(context.Background(), testFairLockKey, WithMaxAttempts(2))
+	if !errors.Is(err, ErrLockAcquisitionFailed) {
+		t.Fatalf("expected ErrLockAcquisitionFailed, got %v", err)
+	}
+	if n, _ := redigo.ListLen(queue); n != 0 {
+		t.Fatalf("expected empty queue, got %d", n)
+	}
+}
+
+func TestRedigo_FairLockWatchdogFollowsExtend(t *testing.T) {
+	redigo := NewRedigo(opts...)
+	queue, timeouts := fairLockKeys(testFairLockKey)
+	_, _ = redigo.Del(testFairLockKey, queue, timeouts)
+
+	lock, err := redigo.LockFair(context.Background(), testFairLockKey, WithLockExpiry(3*time.Second), WithWatchdog(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lock.Unlock()
+	if err = lock.Extend(300 * time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-lock.Lost():
+		t.Fatal("lock lost after Extend")
+	case <-time.After(1200 * time.Millisecond):
+	}
+}
@@ -0,0 +1,47 @@
+package redigo
+
+import (
+	"fmt"
+)
+
+// fencedSetScript sets KEYS[1] to ARGV[1] if the fencing token ARGV[2] is not older than the newest
+// token seen for the key, which is kept in KEYS[2]. It returns 1 if the value was written, 0 otherwise
+var fencedSetScript = NewScript(2, `
+	local newest = tonumber(redis.call("GET", KEYS[2]) or "0")
+	local token = tonumber(ARGV[2])
+	if token < newest then
+		return 0
+	end
+	redis.call("SET", KEYS[2], token)
+	redis.call("SET", KEYS[1], ARGV[1])
+	return 1
+`)
+
+// fencingKey returns the key of the fencing counter of the lock key, the counter never expires
+// so the tokens keep increasing across the holders of the lock
+func fencingKey(key string) string {
+	return key + ":fencing"
+}
+
+// fencedTokenKey returns the key holding the newest fencing token used to write key
+func fencedTokenKey(key string) string {
+	return key + ":token"
+}
+
+// SetFenced sets key to v, encoded like Set, only if token is not older than the newest fencing token
+// used to write key. The token is the one of the lock protecting key (see Lock.Token), a holder whose
+// lock expired while paused gets ErrStaleFencingToken instead of overwriting the newer holder's writes
+func (r *Redigo) SetFenced(key string, v any, token int64) error {
+	data, err := r.encodeValue(v)
+	if err != nil {
+		return err
+	}
+	result, err := r.evalScript(fencedSetScript, key, fencedTokenKey(key), data, token)
+	if err != nil {
+		return err
+	}
+	if result == int64(0) {
+		return fmt.Errorf("%w: %d", ErrStaleFencingToken, token)
+	}
+	return nil
+}
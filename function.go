@@ -0,0 +1,232 @@
+package redigo
+
+import (
+	"fmt"
+	"github.com/gomodule/redigo/redis"
+	"io/fs"
+)
+
+// FunctionLibrary is a library returned by FUNCTION LIST
+type FunctionLibrary struct {
+	Name      string
+	Engine    string
+	Functions []FunctionInfo
+	Code      string // only filled with WithLibraryCode
+}
+
+// FunctionInfo is a function registered by a library
+type FunctionInfo struct {
+	Name        string
+	Description string
+	Flags       []string
+}
+
+// FunctionLoad loads a library (FUNCTION LOAD) and returns its name, use WithFunctionReplace
+// to replace an existing library with the same name
+func (r *Redigo) FunctionLoad(code string, opts ...FunctionOption) (string, error) {
+	options := parseFunctionOptions(opts...)
+	conn, err := r.getConn()
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+
+	var args = []any{"LOAD"}
+	if options.replace {
+		args = append(args, "REPLACE")
+	}
+	args = append(args, code)
+	return redis.String(conn.Do("FUNCTION", args...))
+}
+
+// FunctionLoadFS loads the library stored in file path of fsys (e.g. an embed.FS)
+func (r *Redigo) FunctionLoadFS(fsys fs.FS, path string, opts ...FunctionOption) (string, error) {
+	code, err := fs.ReadFile(fsys, path)
+	if err != nil {
+		return "", err
+	}
+	return r.FunctionLoad(string(code), opts...)
+}
+
+// FunctionList returns the loaded libraries, filtered by WithLibraryName
+// and including the source code with WithLibraryCode
+func (r *Redigo) FunctionList(opts ...FunctionOption) ([]*FunctionLibrary, error) {
+	options := parseFunctionOptions(opts...)
+	conn, err := r.getConn()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	var args = []any{"LIST"}
+	if options.libraryName != "" {
+		args = append(args, "LIBRARYNAME", options.libraryName)
+	}
+	if options.withCode {
+		args = append(args, "WITHCODE")
+	}
+	values, err := redis.Values(conn.Do("FUNCTION", args...))
+	if err != nil {
+		return nil, err
+	}
+
+	var libraries = make([]*FunctionLibrary, 0, len(values))
+	for _, value := range values {
+		lib, err := parseFunctionLibrary(value)
+		if err != nil {
+			return nil, err
+		}
+		libraries = append(libraries, lib)
+	}
+	return libraries, nil
+}
+
+// FunctionDelete deletes a library and all its functions
+func (r *Redigo) FunctionDelete(library string) error {
+	conn, err := r.getConn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return checkOK(conn.Do("FUNCTION", "DELETE", library))
+}
+
+// FunctionDump returns the serialized payload of all loaded libraries
+func (r *Redigo) FunctionDump() ([]byte, error) {
+	conn, err := r.getConn()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	return redis.Bytes(conn.Do("FUNCTION", "DUMP"))
+}
+
+// FunctionRestore restores libraries from a FunctionDump payload,
+// the policy is APPEND by default and can be changed by WithRestorePolicy
+func (r *Redigo) FunctionRestore(payload []byte, opts ...FunctionOption) error {
+	options := parseFunctionOptions(opts...)
+	conn, err := r.getConn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	var args = []any{"RESTORE", payload}
+	if options.restorePolicy != "" {
+		args = append(args, string(options.restorePolicy))
+	}
+	return checkOK(conn.Do("FUNCTION", args...))
+}
+
+// FCall invokes a function (FCALL) and decodes the reply into v like Get, v can be nil to ignore the reply
+func (r *Redigo) FCall(function string, v any, keys []string, args ...any) error {
+	return r.fcall("FCALL", function, v, keys, args...)
+}
+
+// FCallRO invokes a read-only function (FCALL_RO), it can be executed on replicas
+func (r *Redigo) FCallRO(function string, v any, keys []string, args ...any) error {
+	return r.fcall("FCALL_RO", function, v, keys, args...)
+}
+
+func (r *Redigo) fcall(cmd, function string, v any, keys []string, args ...any) error {
+	conn, err := r.getConn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	reply, err := conn.Do(cmd, redis.Args{function, len(keys)}.AddFlat(keys).Add(args...)...)
+	if err != nil {
+		return err
+	}
+	if v == nil {
+		return nil
+	}
+	return r.scanReply(reply, v)
+}
+
+// loadFunctionLibraries 加载WithFunctionLibrary指定的函数库
+func (r *Redigo) loadFunctionLibraries() error {
+	for _, lib := range r.options.functionLibraries {
+		if _, err := r.FunctionLoadFS(lib.fsys, lib.path, WithFunctionReplace()); err != nil {
+			return fmt.Errorf("load function library %s: %w", lib.path, err)
+		}
+	}
+	return nil
+}
+
+// parseFunctionLibrary 解析FUNCTION LIST返回的单个函数库
+func parseFunctionLibrary(reply any) (*FunctionLibrary, error) {
+	values, err := redis.Values(reply, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(values)%2 != 0 {
+		return nil, fmt.Errorf("%w: odd number of library fields", ErrInvalidResponse)
+	}
+
+	var lib = &FunctionLibrary{}
+	for i := 0; i < len(values); i += 2 {
+		field, err := redis.String(values[i], nil)
+		if err != nil {
+			return nil, err
+		}
+		switch field {
+		case "library_name":
+			lib.Name, err = redis.String(values[i+1], nil)
+		case "engine":
+			lib.Engine, err = redis.String(values[i+1], nil)
+		case "library_code":
+			lib.Code, err = redis.String(values[i+1], nil)
+		case "functions":
+			var functions []any
+			functions, err = redis.Values(values[i+1], nil)
+			for _, function := range functions {
+				var info *FunctionInfo
+				if info, err = parseFunctionInfo(function); err != nil {
+					break
+				}
+				lib.Functions = append(lib.Functions, *info)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return lib, nil
+}
+
+// parseFunctionInfo 解析函数库中的单个函数
+func parseFunctionInfo(reply any) (*FunctionInfo, error) {
+	values, err := redis.Values(reply, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(values)%2 != 0 {
+		return nil, fmt.Errorf("%w: odd number of function fields", ErrInvalidResponse)
+	}
+
+	var info = &FunctionInfo{}
+	for i := 0; i < len(values); i += 2 {
+		field, err := redis.String(values[i], nil)
+		if err != nil {
+			return nil, err
+		}
+		switch field {
+		case "name":
+			info.Name, err = redis.String(values[i+1], nil)
+		case "description":
+			if values[i+1] != nil {
+				info.Description, err = redis.String(values[i+1], nil)
+			}
+		case "flags":
+			info.Flags, err = redis.Strings(values[i+1], nil)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return info, nil
+}
@@ -0,0 +1,62 @@
+package redigo
+
+import (
+	"embed"
+	"testing"
+)
+
+const (
+	redigoFunctionKey = "redigoFunctionKey"
+)
+
+//go:embed testdata/redigotest.lua
+var testFunctionFS embed.FS
+
+func TestRedigo_Function(t *testing.T) {
+	if _, err := NewRedigo(opts...).FunctionList(); err != nil {
+		t.Skipf("redis functions not supported: %v", err)
+	}
+	redigo := NewRedigo(append(opts, WithFunctionLibrary(testFunctionFS, "testdata/redigotest.lua"))...)
+
+	var echo string
+	err := redigo.FCall("redigo_echo", &echo, nil, "hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if echo != "hello" {
+		t.Fatalf("unexpected FCALL result %v", echo)
+	}
+
+	err = redigo.Set(redigoFunctionKey, &User{ID: 10086, Name: "dianxin"}, WithEX(expireSeconds))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var user User
+	err = redigo.FCallRO("redigo_get", &user, []string{redigoFunctionKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Name != "dianxin" {
+		t.Fatalf("unexpected FCALL_RO result %+v", user)
+	}
+
+	libs, err := redigo.FunctionList(WithLibraryName("redigotest"), WithLibraryCode())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(libs) != 1 || len(libs[0].Functions) != 2 || libs[0].Code == "" {
+		t.Fatalf("unexpected FUNCTION LIST result %+v", libs)
+	}
+
+	payload, err := redigo.FunctionDump()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = redigo.FunctionDelete("redigotest"); err != nil {
+		t.Fatal(err)
+	}
+	if err = redigo.FunctionRestore(payload, WithRestorePolicy(RestorePolicyReplace)); err != nil {
+		t.Fatal(err)
+	}
+	t.Logf("function libraries %+v", libs[0])
+}
@@ -0,0 +1,59 @@
+package redigo
+
+import (
+	"fmt"
+	"github.com/gomodule/redigo/redis"
+)
+
+// HSet sets the fields of the hash key to the field/value pairs of values (a map[string]T),
+// values are encoded like Set so they are compressed and encrypted as well.
+// It returns the number of fields which were added
+func (r *Redigo) HSet(key string, values any) (int64, error) {
+	args, err := r.msetArgs(values)
+	if err != nil {
+		return 0, err
+	}
+	if len(args) == 0 {
+		return 0, nil
+	}
+	return r.intCmd("HSET", append([]any{key}, args...)...)
+}
+
+// HGet decodes the value of field of the hash key into v like Get,
+// it returns redis.ErrNil if the field does not exist
+func (r *Redigo) HGet(key, field string, v any) error {
+	return r.getInto(v, "HGET", key, field)
+}
+
+// HMGet reads the values of fields of the hash key in one round trip, dest is filled like MGet:
+// a pointer to a map[string]T (or a non-nil map[string]T) indexed by field or a pointer to a []T
+// in the order of fields. The fields that do not exist are returned in missing
+func (r *Redigo) HMGet(key string, fields []string, dest any) (missing []string, err error) {
+	val, err := batchDest(dest)
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) == 0 {
+		return r.scanBatch(val, "field", nil, nil)
+	}
+
+	conn, err := r.getConn()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	var args = make([]any, 0, len(fields)+1)
+	args = append(args, key)
+	for _, field := range fields {
+		args = append(args, field)
+	}
+	values, err := redis.Values(conn.Do("HMGET", args...))
+	if err != nil {
+		return nil, err
+	}
+	if len(values) != len(fields) {
+		return nil, fmt.Errorf("%w: HMGET returned %d values for %d fields", ErrInvalidResponse, len(values), len(fields))
+	}
+	return r.scanBatch(val, "field", fields, values)
+}
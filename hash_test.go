@@ -0,0 +1,77 @@
+package redigo
+
+import (
+	"bytes"
+	"errors"
+	"github.com/gomodule/redigo/redis"
+	"testing"
+)
+
+const (
+	redigoHashKey = "redigoHashKey"
+)
+
+func TestRedigo_Hash(t *testing.T) {
+	redigo := NewRedigo(opts...)
+	_, _ = redigo.Del(redigoHashKey)
+
+	n, err := redigo.HSet(redigoHashKey, map[string]*User{
+		"dianxin":  {ID: 10086, Name: "dianxin"},
+		"liantong": {ID: 10010, Name: "liantong"},
+	})
+	if err != nil || n != 2 {
+		t.Fatalf("HSET returned %v %v", n, err)
+	}
+
+	var user User
+	if err = redigo.HGet(redigoHashKey, "liantong", &user); err != nil || user.ID != 10010 {
+		t.Fatalf("unexpected HGET result %+v (%v)", user, err)
+	}
+	if err = redigo.HGet(redigoHashKey, "yidong", &user); !errors.Is(err, redis.ErrNil) {
+		t.Fatalf("expected redis.ErrNil, got %v", err)
+	}
+
+	var users []User
+	missing, err := redigo.HMGet(redigoHashKey, []string{"dianxin", "yidong", "liantong"}, &users)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 3 || users[0].ID != 10086 || users[2].ID != 10010 {
+		t.Fatalf("unexpected HMGET result %+v", users)
+	}
+	if len(missing) != 1 || missing[0] != "yidong" {
+		t.Fatalf("expected missing [yidong], got %v", missing)
+	}
+	var byField = map[string]User{}
+	if _, err = redigo.HMGet(redigoHashKey, []string{"dianxin"}, byField); err != nil || byField["dianxin"].ID != 10086 {
+		t.Fatalf("unexpected HMGET result %+v (%v)", byField, err)
+	}
+}
+
+func TestRedigo_HashEncryption(t *testing.T) {
+	keyring := NewKeyring()
+	if err := keyring.AddKey("k1", newTestKey(t)); err != nil {
+		t.Fatal(err)
+	}
+	redigo := NewRedigo(append(opts, WithEncryption(keyring))...)
+	_, _ = redigo.Del(redigoHashKey)
+
+	if _, err := redigo.HSet(redigoHashKey, map[string]any{"name": "lory", "age": 18}); err != nil {
+		t.Fatal(err)
+	}
+	raw, err := redis.Bytes(redigo.Do("HGET", redigoHashKey, "name"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(raw, encryptedMagic) || bytes.Contains(raw, []byte("lory")) {
+		t.Fatalf("expected encrypted field, got %q", raw)
+	}
+	var name string
+	if err = redigo.HGet(redigoHashKey, "name", &name); err != nil || name != "lory" {
+		t.Fatalf("expected lory, got %q (%v)", name, err)
+	}
+	var age []int
+	if _, err = redigo.HMGet(redigoHashKey, []string{"age"}, &age); err != nil || age[0] != 18 {
+		t.Fatalf("expected 18, got %v (%v)", age, err)
+	}
+}
@@ -1,11 +1,12 @@
 package redigo
 
 import (
-	"encoding/json"
+	"context"
 	"errors"
 	"fmt"
 	"github.com/gomodule/redigo/redis"
 	"reflect"
+	"strconv"
 )
 
 func isBasicType(v interface{}) bool {
@@ -28,6 +29,138 @@ func isBasicType(v interface{}) bool {
 	}
 }
 
+// encodeValue 将写入的值转换为redis参数, 基础类型原样返回, 其他类型通过编解码器序列化(默认JSON).
+// 启用压缩或加密时基础类型先转换为写入redis的格式, 超过阈值时压缩, 启用加密时再加密
+func (r *Redigo) encodeValue(v any) (any, error) {
+	var data []byte
+	if isBasicType(v) {
+		if !r.packing() {
+			return v, nil
+		}
+		data = argBytes(v)
+	} else {
+		var err error
+		if data, err = r.codec().Marshal(v); err != nil {
+			return nil, err
+		}
+	}
+	return r.packBytes(data)
+}
+
+// argBytes 按redigo写入命令参数的格式将基础类型转换为字节, 与未加密时服务器收到的值一致
+func argBytes(v any) []byte {
+	switch value := v.(type) {
+	case string:
+		return []byte(value)
+	case []byte:
+		return value
+	case int:
+		return strconv.AppendInt(nil, int64(value), 10)
+	case int64:
+		return strconv.AppendInt(nil, value, 10)
+	case float64:
+		return strconv.AppendFloat(nil, value, 'g', -1, 64)
+	case bool:
+		if value {
+			return []byte("1")
+		}
+		return []byte("0")
+	case nil:
+		return []byte{}
+	default:
+		return []byte(fmt.Sprint(v))
+	}
+}
+
+// packing 是否需要对写入的数据做额外处理(压缩或加密)
+func (r *Redigo) packing() bool {
+	return r.options.compressor != nil || r.options.keyring != nil
+}
+
+// packBytes 对序列化后的数据做写入前的处理: 先压缩再加密
+func (r *Redigo) packBytes(data []byte) ([]byte, error) {
+	data, err := r.compress(data)
+	if err != nil {
+		return nil, err
+	}
+	return r.encrypt(data)
+}
+
+// unpackBytes 还原packBytes处理过的数据: 先解密再解压, 未处理的数据原样返回
+func (r *Redigo) unpackBytes(data []byte) ([]byte, error) {
+	data, err := r.options.keyring.decrypt(data)
+	if err != nil {
+		return nil, err
+	}
+	return decompress(data)
+}
+
+// setArgs 根据选项构造SET命令参数
+func (r *Redigo) setArgs(key string, v any, options *setOptions) ([]any, error) {
+	data, err := r.encodeValue(v)
+	if err != nil {
+		return nil, err
+	}
+	var args = []any{
+		key,
+		data,
+	}
+	if options.ex != 0 {
+		args = append(args, "EX", options.ex)
+	} else if options.px != 0 {
+		args = append(args, "PX", options.px)
+	} else if options.exat != 0 {
+		args = append(args, "EXAT", options.exat)
+	} else if options.pxat != 0 {
+		args = append(args, "PXAT", options.pxat)
+	} else if options.keepTTL {
+		args = append(args, "KEEPTTL")
+	}
+	if options.nx {
+		args = append(args, "NX")
+	} else if options.xx {
+		args = append(args, "XX")
+	}
+	if options.get != nil {
+		args = append(args, "GET")
+	}
+	return args, nil
+}
+
+// checkSetReply 检查SET命令的返回值
+func (r *Redigo) checkSetReply(reply any, options *setOptions) error {
+	// GET选项返回旧值: NX时旧值存在表示未设置, XX时nil表示未设置
+	if options.get != nil {
+		if reply == nil {
+			if options.xx {
+				return ErrKeyNotExists
+			}
+			return nil
+		}
+		if err := r.scanReply(reply, options.get); err != nil {
+			return err
+		}
+		if options.nx {
+			return ErrKeyExists
+		}
+		return nil
+	}
+
+	// 处理条件性设置选项的返回值
+	if options.nx || options.xx {
+		if reply == nil {
+			if options.nx {
+				return ErrKeyExists
+			} else {
+				return ErrKeyNotExists
+			}
+		}
+	}
+
+	// 检查是否为标准OK响应
+	return checkOK(reply, nil)
+}
+
 func checkOK(reply any, err error) error {
 	if err != nil {
 		return err
@@ -47,10 +180,20 @@ func (r *Redigo) getConn() (redis.Conn, error) {
 	return conn, nil
 }
 
+func (r *Redigo) getConnContext(ctx context.Context) (redis.Conn, error) {
+	return r.pool.GetContext(ctx)
+}
+
 func (r *Redigo) scanReply(reply any, v any) error {
 	if reply == nil {
 		return redis.ErrNil
 	}
+	if data, ok := reply.([]byte); ok {
+		var err error
+		if reply, err = r.unpackBytes(data); err != nil {
+			return err
+		}
+	}
 	val := reflect.ValueOf(v)
 	if val.Kind() != reflect.Ptr || val.IsNil() {
 		return errors.New("v must be a non-nil pointer")
@@ -96,22 +239,45 @@ func (r *Redigo) scanReply(reply any, v any) error {
 				return err
 			}
 			elemVal.SetBytes(b)
+		} else if values, ok := reply.([]any); ok {
+			// 数组响应逐个元素解码
+			return r.scanValues(values, v)
 		} else {
-			// 其他切片类型尝试JSON反序列化
+			// 其他切片类型通过编解码器反序列化
 			data, err := redis.Bytes(reply, nil)
 			if err != nil {
 				return err
 			}
-			return json.Unmarshal(data, v)
+			return r.codec().Unmarshal(data, v)
 		}
 	default:
-		// 尝试JSON反序列化到结构体
+		// 通过编解码器反序列化到结构体
 		data, err := redis.Bytes(reply, nil)
 		if err != nil {
 			return err
 		}
-		return json.Unmarshal(data, v)
+		return r.codec().Unmarshal(data, v)
+	}
+	return nil
+}
+
+// scanValues 将数组响应逐个元素解码到切片指针v中, nil元素保留零值
+func (r *Redigo) scanValues(values []any, v any) error {
+	val := reflect.ValueOf(v)
+	if val.Kind() != reflect.Ptr || val.IsNil() || val.Elem().Kind() != reflect.Slice {
+		return errors.New("v must be a non-nil pointer to slice")
+	}
+	sliceVal := val.Elem()
+	list := reflect.MakeSlice(sliceVal.Type(), len(values), len(values))
+	for i, value := range values {
+		if value == nil {
+			continue
+		}
+		if err := r.scanReply(value, list.Index(i).Addr().Interface()); err != nil {
+			return err
+		}
 	}
+	sliceVal.Set(list)
 	return nil
 }
 
@@ -0,0 +1,269 @@
+package redigo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/gomodule/redigo/redis"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// forceUnlockScript deletes the lock whoever holds it and publishes the key to the channel ARGV[1].
+// It returns 1 if the lock was deleted, 0 if it was not held
+var forceUnlockScript = NewScript(1, `
+	if redis.call("DEL", KEYS[1]) == 1 then
+		redis.call("PUBLISH", ARGV[1], KEYS[1])
+		return 1
+	end
+	return 0
+`)
+
+// lockMetadata is the owner metadata stored as JSON in the lock value with WithLockMetadata
+type lockMetadata struct {
+	Token      string    `json:"token"`
+	Host       string    `json:"host,omitempty"`
+	PID        int       `json:"pid,omitempty"`
+	AcquiredAt time.Time `json:"acquired_at"`
+	Purpose    string    `json:"purpose,omitempty"`
+}
+
+// LockInfo describes the current holder of a lock
+type LockInfo struct {
+	Key        string        // key of the lock
+	Value      string        // raw value identifying the holder
+	Host       string        // host name of the holder, with WithLockMetadata only
+	PID        int           // process ID of the holder, with WithLockMetadata only
+	AcquiredAt time.Time     // acquisition time, with WithLockMetadata only
+	Purpose    string        // purpose given to WithLockMetadata
+	TTL        time.Duration // remaining time to live, NoExpiration if the key has no expiry
+}
+
+// LockStats is a snapshot of the lock acquisition counters of a Redigo
+type LockStats struct {
+	Acquisitions int64         // successful acquisitions
+	Failures     int64         // acquisitions failed after the attempts were exhausted or on error
+	Timeouts     int64         // acquisitions abandoned because the context was done
+	WaitTime     time.Duration // total time spent acquiring, successfully or not
+}
+
+// lockMetrics 统计加锁次数、等待时间和超时次数
+type lockMetrics struct {
+	acquisitions atomic.Int64
+	failures     atomic.Int64
+	timeouts     atomic.Int64
+	waitTime     atomic.Int64
+}
+
+func (m *lockMetrics) record(wait time.Duration, err error) {
+	m.waitTime.Add(int64(wait))
+	switch {
+	case err == nil:
+		m.acquisitions.Add(1)
+	case errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded):
+		m.timeouts.Add(1)
+	default:
+		m.failures.Add(1)
+	}
+}
+
+// newLockValue generates the value of a lock, the owner metadata is included with WithLockMetadata
+func newLockValue(options *lockOptions) (string, error) {
+	token, err := randomValue()
+	if err != nil || !options.metadata {
+		return token, err
+	}
+	host, _ := os.Hostname()
+	data, err := json.Marshal(&lockMetadata{
+		Token:      token,
+		Host:       host,
+		PID:        os.Getpid(),
+		AcquiredAt: time.Now(),
+		Purpose:    options.purpose,
+	})
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// LockStats returns the lock acquisition counters of all the lock types except Redlock
+func (r *Redigo) LockStats() LockStats {
+	return LockStats{
+		Acquisitions: r.metrics.acquisitions.Load(),
+		Failures:     r.metrics.failures.Load(),
+		Timeouts:     r.metrics.timeouts.Load(),
+		WaitTime:     time.Duration(r.metrics.waitTime.Load()),
+	}
+}
+
+// GetLockInfo returns the holder of the lock key and its remaining time to live,
+// ErrKeyNotExists if the lock is not held
+func (r *Redigo) GetLockInfo(key string) (*LockInfo, error) {
+	conn, err := r.getConn()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	_ = conn.Send("GET", key)
+	_ = conn.Send("PTTL", key)
+	if err = conn.Flush(); err != nil {
+		return nil, err
+	}
+	value, err := redis.String(conn.Receive())
+	if errors.Is(err, redis.ErrNil) {
+		_, _ = conn.Receive()
+		return nil, ErrKeyNotExists
+	}
+	if err != nil {
+		_, _ = conn.Receive()
+		return nil, err
+	}
+	ttl, err := redis.Int64(conn.Receive())
+	if err != nil {
+		return nil, err
+	}
+	if ttl == -2 {
+		return nil, ErrKeyNotExists
+	}
+
+	info := newLockInfo(key, value, ttl)
+	if metadata, ok := parseLockMetadata(value); ok {
+		info.setMetadata(metadata)
+	}
+	return info, nil
+}
+
+// ListLocks returns the holders of the locks whose key starts with prefix. The keys are found with SCAN,
+// only the locks acquired WithLockMetadata are reported since the other string keys with an expiry cannot
+// be told apart from the locks
+func (r *Redigo) ListLocks(prefix string) ([]*LockInfo, error) {
+	conn, err := r.getConn()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	// SCAN和每批key的查询使用同一个连接
+	var locks []*LockInfo
+	err = scanKeys(context.Background(), conn, escapePattern(prefix)+"*", "string", func(keys []string) error {
+		// 每批key的GET和PTTL在一次往返中完成
+		for _, key := range keys {
+			_ = conn.Send("GET", key)
+			_ = conn.Send("PTTL", key)
+		}
+		if err := conn.Flush(); err != nil {
+			return err
+		}
+		for _, key := range keys {
+			value, err := redis.String(conn.Receive())
+			ttl, ttlErr := redis.Int64(conn.Receive())
+			if err != nil {
+				// 扫描之后已被释放或类型已改变
+				if _, ok := err.(redis.Error); ok || errors.Is(err, redis.ErrNil) {
+					continue
+				}
+				return err
+			}
+			if ttlErr != nil {
+				return ttlErr
+			}
+			metadata, ok := parseLockMetadata(value)
+			if !ok || ttl < 0 {
+				continue
+			}
+			info := newLockInfo(key, value, ttl)
+			info.setMetadata(metadata)
+			locks = append(locks, info)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return locks, nil
+}
+
+func newLockInfo(key, value string, ttl int64) *LockInfo {
+	info := &LockInfo{
+		Key:   key,
+		Value: value,
+		TTL:   NoExpiration,
+	}
+	if ttl >= 0 {
+		info.TTL = time.Duration(ttl) * time.Millisecond
+	}
+	return info
+}
+
+func (info *LockInfo) setMetadata(metadata *lockMetadata) {
+	info.Host = metadata.Host
+	info.PID = metadata.PID
+	info.AcquiredAt = metadata.AcquiredAt
+	info.Purpose = metadata.Purpose
+}
+
+// parseLockMetadata 解析WithLockMetadata写入的锁值, 非锁的值返回false
+func parseLockMetadata(value string) (*lockMetadata, bool) {
+	if !strings.HasPrefix(value, "{") {
+		return nil, false
+	}
+	var metadata lockMetadata
+	if json.Unmarshal([]byte(value), &metadata) != nil || metadata.Token == "" || metadata.AcquiredAt.IsZero() {
+		return nil, false
+	}
+	return &metadata, true
+}
+
+// scanKeys 在conn上通过SCAN遍历匹配pattern的key, 每批key交给fn处理, keyType不为空时只返回该类型的key.
+// fn可以使用同一个连接, 调用fn时SCAN的响应已读取完毕
+func scanKeys(ctx context.Context, conn redis.Conn, pattern, keyType string, fn func(keys []string) error) error {
+	for cursor := int64(0); ; {
+		args := redis.Args{cursor, "MATCH", pattern, "COUNT", 100}
+		if keyType != "" {
+			args = args.Add("TYPE", keyType)
+		}
+		values, err := redis.Values(redis.DoContext(conn, ctx, "SCAN", args...))
+		if err != nil {
+			return err
+		}
+		var keys []string
+		if _, err = redis.Scan(values, &cursor, &keys); err != nil {
+			return err
+		}
+		if len(keys) > 0 {
+			if err = fn(keys); err != nil {
+				return err
+			}
+		}
+		if cursor == 0 {
+			return nil
+		}
+	}
+}
+
+// escapePattern 转义glob模式的特殊字符
+func escapePattern(s string) string {
+	var b strings.Builder
+	for _, c := range s {
+		switch c {
+		case '*', '?', '[', ']', '\\':
+			b.WriteRune('\\')
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
+// ForceUnlock deletes the lock key whoever holds it and wakes up its waiters, it is meant for
+// operators releasing a stuck lock. It returns false if the lock was not held
+func (r *Redigo) ForceUnlock(key string) (bool, error) {
+	result, err := r.evalScript(forceUnlockScript, key, lockChannel(key))
+	if err != nil {
+		return false, err
+	}
+	return result == int64(1), nil
+}
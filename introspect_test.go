@@ -0,0 +1,123 @@
+package redigo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+)
+
+const (
+	testIntrospectLockPrefix = "test_introspect_lock:"
+)
+
+func TestRedigo_GetLockInfo(t *testing.T) {
+	redigo := NewRedigo(opts...)
+	key := testIntrospectLockPrefix + "a"
+	_, _ = redigo.Del(key, fencingKey(key))
+
+	lock, err := redigo.LockContext(context.Background(), key, WithLockExpiry(5*time.Second), WithLockMetadata("nightly report"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lock.Unlock()
+
+	info, err := redigo.GetLockInfo(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Value != lock.Value() || info.Purpose != "nightly report" || info.PID != os.Getpid() {
+		t.Fatalf("unexpected lock info %+v", info)
+	}
+	if info.TTL <= 0 || info.TTL > 5*time.Second || time.Since(info.AcquiredAt) > time.Minute {
+		t.Fatalf("unexpected lock info %+v", info)
+	}
+
+	// 有过期时间的普通值和未带元数据的锁不会被列出
+	cacheKey := testIntrospectLockPrefix + "cache"
+	if err = redigo.Set(cacheKey, "cached", WithExpiration(5*time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	defer redigo.Del(cacheKey)
+	plain, err := redigo.LockContext(context.Background(), testIntrospectLockPrefix+"plain", WithLockExpiry(5*time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer plain.Unlock()
+
+	locks, err := redigo.ListLocks(testIntrospectLockPrefix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(locks) != 1 || locks[0].Key != key {
+		t.Fatalf("expected lock %s, got %+v", key, locks)
+	}
+
+	// 强制释放后锁不存在
+	if ok, err := redigo.ForceUnlock(key); err != nil || !ok {
+		t.Fatalf("expected force unlock, got %v (%v)", ok, err)
+	}
+	if _, err = redigo.GetLockInfo(key); !errors.Is(err, ErrKeyNotExists) {
+		t.Fatalf("expected ErrKeyNotExists, got %v", err)
+	}
+	if err = lock.Unlock(); !errors.Is(err, ErrLockNotHeld) {
+		t.Fatalf("expected ErrLockNotHeld, got %v", err)
+	}
+}
+
+func TestRedigo_ListLocksSingleConn(t *testing.T) {
+	// 连接池只有一个连接时ListLocks不能等待第二个连接
+	redigo := NewRedigo(append(opts, WithMaxIdle(1), WithMaxActive(1))...)
+	key := testIntrospectLockPrefix + "single"
+	lock, err := redigo.LockContext(context.Background(), key, WithLockExpiry(5*time.Second), WithLockMetadata("single"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		locks, err := redigo.ListLocks(testIntrospectLockPrefix)
+		if err == nil && (len(locks) != 1 || locks[0].Key != key) {
+			err = fmt.Errorf("expected lock %s, got %+v", key, locks)
+		}
+		done <- err
+	}()
+	select {
+	case err = <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(3 * time.Second):
+		// 连接仍被占用, 不再释放锁, 等待其过期
+		t.Fatal("ListLocks is blocked waiting for a connection")
+	}
+	if err = lock.Unlock(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRedigo_LockStats(t *testing.T) {
+	redigo := NewRedigo(opts...)
+	key := testIntrospectLockPrefix + "b"
+	_, _ = redigo.Del(key)
+
+	lock, err := redigo.LockContext(context.Background(), key, WithLockExpiry(5*time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lock.Unlock()
+	_, _ = redigo.LockContext(context.Background(), key, WithMaxAttempts(1))
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, _ = redigo.LockContext(ctx, key)
+
+	stats := redigo.LockStats()
+	if stats.Acquisitions != 1 || stats.Failures != 1 || stats.Timeouts != 1 {
+		t.Fatalf("unexpected lock stats %+v", stats)
+	}
+	if stats.WaitTime < 100*time.Millisecond {
+		t.Fatalf("expected wait time of at least 100ms, got %v", stats.WaitTime)
+	}
+}
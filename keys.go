@@ -0,0 +1,233 @@
+package redigo
+
+import (
+	"github.com/gomodule/redigo/redis"
+	"time"
+)
+
+const (
+	// NoExpiration is returned by PTTL when the key exists but has no associated expire
+	NoExpiration = time.Duration(-1)
+)
+
+// Del removes the keys and returns the number of keys removed
+func (r *Redigo) Del(keys ...string) (int64, error) {
+	return r.keysCount("DEL", keys...)
+}
+
+// Unlink removes the keys like Del but reclaims the memory in a background thread
+func (r *Redigo) Unlink(keys ...string) (int64, error) {
+	return r.keysCount("UNLINK", keys...)
+}
+
+// Exists returns the number of keys existing, a key given several times is counted several times
+func (r *Redigo) Exists(keys ...string) (int64, error) {
+	return r.keysCount("EXISTS", keys...)
+}
+
+// Touch updates the last access time of the keys and returns the number of keys existing
+func (r *Redigo) Touch(keys ...string) (int64, error) {
+	return r.keysCount("TOUCH", keys...)
+}
+
+// keysCount 执行多key命令并返回整数结果
+func (r *Redigo) keysCount(cmd string, keys ...string) (int64, error) {
+	conn, err := r.getConn()
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	reply, err := conn.Do(cmd, redis.Args{}.AddFlat(keys)...)
+	if err != nil {
+		return 0, err
+	}
+
+	var ret int64
+	if err = r.scanReply(reply, &ret); err != nil {
+		return 0, err
+	}
+	return ret, nil
+}
+
+// TTL returns the remaining time to live of key in seconds,
+// -1 if the key exists but has no associated expire and -2 if the key does not exist
+func (r *Redigo) TTL(key string) (int64, error) {
+	conn, err := r.getConn()
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	reply, err := conn.Do("TTL", key)
+	if err != nil {
+		return 0, err
+	}
+
+	var ttl int64
+	if err = r.scanReply(reply, &ttl); err != nil {
+		return 0, err
+	}
+	return ttl, nil
+}
+
+// PTTL returns the remaining time to live of key in milliseconds precision,
+// NoExpiration if the key has no associated expire and ErrKeyNotExists if the key does not exist
+func (r *Redigo) PTTL(key string) (time.Duration, error) {
+	conn, err := r.getConn()
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	ttl, err := redis.Int64(conn.Do("PTTL", key))
+	if err != nil {
+		return 0, err
+	}
+	switch ttl {
+	case -2:
+		return 0, ErrKeyNotExists
+	case -1:
+		return NoExpiration, nil
+	}
+	return time.Duration(ttl) * time.Millisecond, nil
+}
+
+// ExpireTime returns the absolute time at which key will expire, the zero time if the key
+// has no associated expire and ErrKeyNotExists if the key does not exist
+func (r *Redigo) ExpireTime(key string) (time.Time, error) {
+	conn, err := r.getConn()
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer conn.Close()
+
+	ms, err := redis.Int64(conn.Do("PEXPIRETIME", key))
+	if err != nil {
+		return time.Time{}, err
+	}
+	switch ms {
+	case -2:
+		return time.Time{}, ErrKeyNotExists
+	case -1:
+		return time.Time{}, nil
+	}
+	return time.UnixMilli(ms), nil
+}
+
+// Expire sets a timeout on key, returns false if the key does not exist or the timeout was not set
+// because of the NX/XX/GT/LT condition. A timeout which is not a whole number of seconds is set in
+// milliseconds (PEXPIRE) instead of being truncated
+func (r *Redigo) Expire(key string, expiration time.Duration, opts ...ExpireOption) (bool, error) {
+	if expiration%time.Second != 0 {
+		return r.PExpire(key, expiration, opts...)
+	}
+	return r.expire("EXPIRE", key, int64(expiration/time.Second), opts...)
+}
+
+// PExpire sets a timeout in milliseconds on key like Expire
+func (r *Redigo) PExpire(key string, expiration time.Duration, opts ...ExpireOption) (bool, error) {
+	return r.expire("PEXPIRE", key, expiration.Milliseconds(), opts...)
+}
+
+// ExpireAt sets key to expire at tm in seconds precision like Expire
+func (r *Redigo) ExpireAt(key string, tm time.Time, opts ...ExpireOption) (bool, error) {
+	return r.expire("EXPIREAT", key, tm.Unix(), opts...)
+}
+
+// PExpireAt sets key to expire at tm in milliseconds precision like Expire
+func (r *Redigo) PExpireAt(key string, tm time.Time, opts ...ExpireOption) (bool, error) {
+	return r.expire("PEXPIREAT", key, tm.UnixMilli(), opts...)
+}
+
+func (r *Redigo) expire(cmd, key string, value int64, opts ...ExpireOption) (bool, error) {
+	options := parseExpireOptions(opts...)
+	conn, err := r.getConn()
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+
+	var args = []any{key, value}
+	if options.condition != "" {
+		args = append(args, options.condition)
+	}
+	return redis.Bool(conn.Do(cmd, args...))
+}
+
+// Persist removes the timeout of key, returns false if the key does not exist or has no timeout
+func (r *Redigo) Persist(key string) (bool, error) {
+	conn, err := r.getConn()
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+
+	return redis.Bool(conn.Do("PERSIST", key))
+}
+
+// Type returns the type of the value stored at key (string, list, set, zset, hash and stream),
+// none if the key does not exist
+func (r *Redigo) Type(key string) (string, error) {
+	conn, err := r.getConn()
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+
+	return redis.String(conn.Do("TYPE", key))
+}
+
+// Rename renames key to newKey, newKey is overwritten if it already exists
+func (r *Redigo) Rename(key, newKey string) error {
+	conn, err := r.getConn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return checkOK(conn.Do("RENAME", key, newKey))
+}
+
+// RenameNX renames key to newKey only if newKey does not exist, returns false if newKey already exists
+func (r *Redigo) RenameNX(key, newKey string) (bool, error) {
+	conn, err := r.getConn()
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+
+	return redis.Bool(conn.Do("RENAMENX", key, newKey))
+}
+
+// Copy copies the value stored at source to destination, returns false if nothing was copied
+// because the destination already exists (see WithCopyReplace)
+func (r *Redigo) Copy(source, destination string, opts ...CopyOption) (bool, error) {
+	options := parseCopyOptions(opts...)
+	conn, err := r.getConn()
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+
+	var args = []any{source, destination}
+	if options.db != nil {
+		args = append(args, "DB", *options.db)
+	}
+	if options.replace {
+		args = append(args, "REPLACE")
+	}
+	return redis.Bool(conn.Do("COPY", args...))
+}
+
+// ObjectEncoding returns the internal encoding of the value stored at key,
+// redis.ErrNil if the key does not exist
+func (r *Redigo) ObjectEncoding(key string) (string, error) {
+	conn, err := r.getConn()
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+
+	return redis.String(conn.Do("OBJECT", "ENCODING", key))
+}
@@ -0,0 +1,95 @@
+package redigo
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+const (
+	redigoKeysKey    = "redigoKeysKey"
+	redigoKeysNewKey = "redigoKeysNewKey"
+)
+
+func TestRedigo_Keys(t *testing.T) {
+	redigo := NewRedigo(opts...)
+	_, err := redigo.Del(redigoKeysKey, redigoKeysNewKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = redigo.Set(redigoKeysKey, "value"); err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := redigo.Exists(redigoKeysKey, redigoNotFoundKey, redigoKeysKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Fatalf("expected EXISTS 2, got %d", n)
+	}
+
+	ok, err := redigo.Expire(redigoKeysKey, time.Minute)
+	if err != nil || !ok {
+		t.Fatalf("EXPIRE returned %v %v", ok, err)
+	}
+	ok, err = redigo.Expire(redigoKeysKey, time.Hour, WithExpireLT())
+	if err != nil || ok {
+		t.Fatalf("EXPIRE LT returned %v %v", ok, err)
+	}
+	ttl, err := redigo.PTTL(redigoKeysKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ttl <= 0 || ttl > time.Minute {
+		t.Fatalf("unexpected PTTL %v", ttl)
+	}
+
+	// 不足一秒的过期时间按毫秒设置, 不会被截断为0而删除key
+	ok, err = redigo.Expire(redigoKeysKey, 1500*time.Millisecond)
+	if err != nil || !ok {
+		t.Fatalf("EXPIRE returned %v %v", ok, err)
+	}
+	if ttl, err = redigo.PTTL(redigoKeysKey); err != nil || ttl <= time.Second || ttl > 1500*time.Millisecond {
+		t.Fatalf("unexpected PTTL %v (%v)", ttl, err)
+	}
+	if ok, err = redigo.Expire(redigoKeysKey, 500*time.Millisecond); err != nil || !ok {
+		t.Fatalf("EXPIRE returned %v %v", ok, err)
+	}
+	if n, err = redigo.Exists(redigoKeysKey); err != nil || n != 1 {
+		t.Fatalf("expected the key to survive a sub-second expire, got %d (%v)", n, err)
+	}
+
+	ok, err = redigo.Persist(redigoKeysKey)
+	if err != nil || !ok {
+		t.Fatalf("PERSIST returned %v %v", ok, err)
+	}
+	if ttl, err = redigo.PTTL(redigoKeysKey); err != nil || ttl != NoExpiration {
+		t.Fatalf("PTTL returned %v %v", ttl, err)
+	}
+	if _, err = redigo.PTTL(redigoNotFoundKey); !errors.Is(err, ErrKeyNotExists) {
+		t.Fatalf("expected ErrKeyNotExists, got %v", err)
+	}
+
+	typ, err := redigo.Type(redigoKeysKey)
+	if err != nil || typ != "string" {
+		t.Fatalf("TYPE returned %v %v", typ, err)
+	}
+
+	if err = redigo.Rename(redigoKeysKey, redigoKeysNewKey); err != nil {
+		t.Fatal(err)
+	}
+	ok, err = redigo.Copy(redigoKeysNewKey, redigoKeysKey)
+	if err != nil || !ok {
+		t.Fatalf("COPY returned %v %v", ok, err)
+	}
+	ok, err = redigo.RenameNX(redigoKeysKey, redigoKeysNewKey)
+	if err != nil || ok {
+		t.Fatalf("RENAMENX returned %v %v", ok, err)
+	}
+
+	n, err = redigo.Unlink(redigoKeysKey, redigoKeysNewKey)
+	if err != nil || n != 2 {
+		t.Fatalf("UNLINK returned %v %v", n, err)
+	}
+}
@@ -0,0 +1,297 @@
+package redigo
+
+import (
+	"fmt"
+	"github.com/gomodule/redigo/redis"
+	"time"
+)
+
+// ListDirection is the side of a list used by LMOVE/BLMOVE
+type ListDirection string
+
+const (
+	ListLeft  ListDirection = "LEFT"
+	ListRight ListDirection = "RIGHT"
+)
+
+// ListPush pushes v at the head of the list (at the tail with WithRright), a slice is pushed
+// element by element with WithUnwind. Elements are encoded like Set so structs are stored as JSON
+func (r *Redigo) ListPush(key string, v any, opts ...ListOption) (ret int64, err error) {
+	options := parseListOptions(opts...)
+	cmd, args, err := r.listPushArgs(key, v, options)
+	if err != nil {
+		return 0, err
+	}
+	return r.intCmd(cmd, args...)
+}
+
+// listPushArgs 构造LPUSH/RPUSH命令及参数
+func (r *Redigo) listPushArgs(key string, v any, options *listOptions) (string, []any, error) {
+	var values []any
+	if options.unwind {
+		values = unwind(v)
+	} else {
+		values = []any{v}
+	}
+	args, err := r.encodeArgs(key, values...)
+	if err != nil {
+		return "", nil, err
+	}
+	if options.right {
+		return "RPUSH", args, nil
+	}
+	return "LPUSH", args, nil
+}
+
+// encodeArgs 构造 key value1 value2 ... 参数, 值的编码方式与Set相同
+func (r *Redigo) encodeArgs(key string, values ...any) ([]any, error) {
+	var args = make([]any, 0, len(values)+1)
+	args = append(args, key)
+	for _, value := range values {
+		data, err := r.encodeValue(value)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, data)
+	}
+	return args, nil
+}
+
+// 阻塞模式(BRPOP/BLPOP) n表示超时时间(s), 否则n表示取值个数
+// v must be a pointer to slice, the elements are decoded like Get
+func (r *Redigo) ListPop(key string, n int, v any, opts ...ListOption) (err error) {
+	options := parseListOptions(opts...)
+	conn, err := r.getConn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	var cmd string
+	if options.right {
+		cmd = "RPOP"
+	} else {
+		cmd = "LPOP"
+	}
+	if options.block {
+		cmd = "B" + cmd
+	}
+	values, err := redis.Values(conn.Do(cmd, key, n))
+	if err != nil {
+		return err
+	}
+
+	// 阻塞模式返回[key, value]
+	if options.block {
+		if len(values) != 2 {
+			return fmt.Errorf("%w: %v", ErrInvalidResponse, values)
+		}
+		values = values[1:]
+	}
+	// 将结果扫描到切片
+	return r.scanValues(values, v)
+}
+
+// ListLen returns the length of the list
+func (r *Redigo) ListLen(key string) (n int64, err error) {
+	return r.intCmd("LLEN", key)
+}
+
+// ListRange decodes the elements of the list between start and stop into v (a pointer to slice)
+func (r *Redigo) ListRange(key string, start, stop int64, v any) (err error) {
+	conn, err := r.getConn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// 执行 LRANGE 命令
+	values, err := redis.Values(conn.Do("LRANGE", key, start, stop))
+	if err != nil {
+		return err
+	}
+
+	// 将结果扫描到切片
+	return r.scanValues(values, v)
+}
+
+// BLPop pops an element from the head of the first non-empty list of keys and decodes it into v,
+// blocking up to timeout (zero blocks indefinitely). It returns the key the element was popped from,
+// or redis.ErrNil when the timeout expired
+func (r *Redigo) BLPop(timeout time.Duration, v any, keys ...string) (string, error) {
+	return r.bpop("BLPOP", timeout, v, keys...)
+}
+
+// BRPop pops an element from the tail of the first non-empty list of keys, see BLPop
+func (r *Redigo) BRPop(timeout time.Duration, v any, keys ...string) (string, error) {
+	return r.bpop("BRPOP", timeout, v, keys...)
+}
+
+func (r *Redigo) bpop(cmd string, timeout time.Duration, v any, keys ...string) (string, error) {
+	conn, err := r.getConn()
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+
+	values, err := redis.Values(conn.Do(cmd, redis.Args{}.AddFlat(keys).Add(timeout.Seconds())...))
+	if err != nil {
+		return "", err
+	}
+	if len(values) != 2 {
+		return "", fmt.Errorf("%w: %v", ErrInvalidResponse, values)
+	}
+	key, err := redis.String(values[0], nil)
+	if err != nil {
+		return "", err
+	}
+	return key, r.scanReply(values[1], v)
+}
+
+// LMPop pops up to count elements from the first non-empty list of keys, from the head
+// (from the tail with WithRright), and decodes them into v (a pointer to slice).
+// It returns the key the elements were popped from, or redis.ErrNil when all the lists are empty
+func (r *Redigo) LMPop(keys []string, count int, v any, opts ...ListOption) (string, error) {
+	options := parseListOptions(opts...)
+	args := redis.Args{len(keys)}.AddFlat(keys)
+	return r.mpop("LMPOP", args, count, v, options)
+}
+
+// BLMPop is the blocking variant of LMPop, blocking up to timeout (zero blocks indefinitely)
+func (r *Redigo) BLMPop(timeout time.Duration, keys []string, count int, v any, opts ...ListOption) (string, error) {
+	options := parseListOptions(opts...)
+	args := redis.Args{timeout.Seconds(), len(keys)}.AddFlat(keys)
+	return r.mpop("BLMPOP", args, count, v, options)
+}
+
+func (r *Redigo) mpop(cmd string, args redis.Args, count int, v any, options *listOptions) (string, error) {
+	if options.right {
+		args = args.Add(ListRight)
+	} else {
+		args = args.Add(ListLeft)
+	}
+	if count > 0 {
+		args = args.Add("COUNT", count)
+	}
+
+	conn, err := r.getConn()
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+
+	values, err := redis.Values(conn.Do(cmd, args...))
+	if err != nil {
+		return "", err
+	}
+	if len(values) != 2 {
+		return "", fmt.Errorf("%w: %v", ErrInvalidResponse, values)
+	}
+	key, err := redis.String(values[0], nil)
+	if err != nil {
+		return "", err
+	}
+	elements, err := redis.Values(values[1], nil)
+	if err != nil {
+		return "", err
+	}
+	return key, r.scanValues(elements, v)
+}
+
+// LMove pops an element from the from side of source, pushes it at the to side of destination
+// and decodes it into v. It returns redis.ErrNil if source is empty
+func (r *Redigo) LMove(source, destination string, from, to ListDirection, v any) error {
+	return r.getInto(v, "LMOVE", source, destination, from, to)
+}
+
+// BLMove is the blocking variant of LMove, blocking up to timeout (zero blocks indefinitely)
+func (r *Redigo) BLMove(source, destination string, from, to ListDirection, timeout time.Duration, v any) error {
+	return r.getInto(v, "BLMOVE", source, destination, from, to, timeout.Seconds())
+}
+
+// LPos returns the index of the first element equal to element, redis.ErrNil if there is none
+func (r *Redigo) LPos(key string, element any) (int64, error) {
+	args, err := r.encodeArgs(key, element)
+	if err != nil {
+		return 0, err
+	}
+	return r.intCmd("LPOS", args...)
+}
+
+// LInsert inserts element before (or after) pivot and returns the new length of the list,
+// -1 if pivot was not found
+func (r *Redigo) LInsert(key string, before bool, pivot, element any) (int64, error) {
+	pivotData, err := r.encodeValue(pivot)
+	if err != nil {
+		return 0, err
+	}
+	data, err := r.encodeValue(element)
+	if err != nil {
+		return 0, err
+	}
+	var where = "AFTER"
+	if before {
+		where = "BEFORE"
+	}
+	return r.intCmd("LINSERT", key, where, pivotData, data)
+}
+
+// LSet sets the element at index of the list to v
+func (r *Redigo) LSet(key string, index int64, v any) error {
+	data, err := r.encodeValue(v)
+	if err != nil {
+		return err
+	}
+
+	conn, err := r.getConn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return checkOK(conn.Do("LSET", key, index, data))
+}
+
+// LIndex decodes the element at index of the list into v, redis.ErrNil if index is out of range
+func (r *Redigo) LIndex(key string, index int64, v any) error {
+	return r.getInto(v, "LINDEX", key, index)
+}
+
+// LRem removes count occurrences of element (all of them if count is zero, from the tail if count
+// is negative) and returns the number of removed elements
+func (r *Redigo) LRem(key string, count int64, element any) (int64, error) {
+	data, err := r.encodeValue(element)
+	if err != nil {
+		return 0, err
+	}
+	return r.intCmd("LREM", key, count, data)
+}
+
+// LTrim trims the list to the elements between start and stop
+func (r *Redigo) LTrim(key string, start, stop int64) error {
+	conn, err := r.getConn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return checkOK(conn.Do("LTRIM", key, start, stop))
+}
+
+// LPushX pushes values at the head of the list only if it exists, returns the new length (0 if not exists)
+func (r *Redigo) LPushX(key string, values ...any) (int64, error) {
+	args, err := r.encodeArgs(key, values...)
+	if err != nil {
+		return 0, err
+	}
+	return r.intCmd("LPUSHX", args...)
+}
+
+// RPushX pushes values at the tail of the list only if it exists, returns the new length (0 if not exists)
+func (r *Redigo) RPushX(key string, values ...any) (int64, error) {
+	args, err := r.encodeArgs(key, values...)
+	if err != nil {
+		return 0, err
+	}
+	return r.intCmd("RPUSHX", args...)
+}
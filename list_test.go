@@ -0,0 +1,94 @@
+package redigo
+
+import (
+	"errors"
+	"github.com/gomodule/redigo/redis"
+	"testing"
+	"time"
+)
+
+const (
+	redigoListKey2 = "redigoListKey2"
+)
+
+func TestRedigo_ListPopBlock(t *testing.T) {
+	redigo := NewRedigo(opts...)
+	_, err := redigo.Del(redigoListKey, redigoListKey2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	users := []*User{{ID: 10086, Name: "dianxin"}, {ID: 10010, Name: "liantong"}}
+	_, err = redigo.ListPush(redigoListKey2, users, WithUnwind(), WithRright())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var popped []User
+	err = redigo.ListPop(redigoListKey2, 1, &popped, WithBlock())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(popped) != 1 || popped[0].ID != 10086 {
+		t.Fatalf("unexpected BLPOP result %+v", popped)
+	}
+
+	var user User
+	key, err := redigo.BRPop(time.Second, &user, redigoListKey, redigoListKey2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key != redigoListKey2 || user.ID != 10010 {
+		t.Fatalf("unexpected BRPOP result %v %+v", key, user)
+	}
+	if _, err = redigo.BLPop(100*time.Millisecond, &user, redigoListKey2); !errors.Is(err, redis.ErrNil) {
+		t.Fatalf("expected redis.ErrNil on timeout, got %v", err)
+	}
+}
+
+func TestRedigo_ListCommands(t *testing.T) {
+	redigo := NewRedigo(opts...)
+	_, err := redigo.Del(redigoListKey, redigoListKey2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := redigo.LPushX(redigoListKey, "a")
+	if err != nil || n != 0 {
+		t.Fatalf("LPUSHX returned %v %v", n, err)
+	}
+	_, err = redigo.ListPush(redigoListKey, []string{"a", "b", "c", "d"}, WithUnwind(), WithRright())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, err = redigo.LPos(redigoListKey, "c"); err != nil || n != 2 {
+		t.Fatalf("LPOS returned %v %v", n, err)
+	}
+	if n, err = redigo.LInsert(redigoListKey, true, "c", "x"); err != nil || n != 5 {
+		t.Fatalf("LINSERT returned %v %v", n, err)
+	}
+	if err = redigo.LSet(redigoListKey, 0, "z"); err != nil {
+		t.Fatal(err)
+	}
+	var s string
+	if err = redigo.LIndex(redigoListKey, 0, &s); err != nil || s != "z" {
+		t.Fatalf("LINDEX returned %v %v", s, err)
+	}
+	if n, err = redigo.LRem(redigoListKey, 0, "x"); err != nil || n != 1 {
+		t.Fatalf("LREM returned %v %v", n, err)
+	}
+	if err = redigo.LMove(redigoListKey, redigoListKey2, ListRight, ListLeft, &s); err != nil || s != "d" {
+		t.Fatalf("LMOVE returned %v %v", s, err)
+	}
+	if err = redigo.LTrim(redigoListKey, 0, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	var list []string
+	key, err := redigo.LMPop([]string{redigoNotFoundKey, redigoListKey}, 10, &list)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key != redigoListKey || len(list) != 2 || list[0] != "z" || list[1] != "b" {
+		t.Fatalf("unexpected LMPOP result %v %+v", key, list)
+	}
+}
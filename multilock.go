@@ -0,0 +1,163 @@
+package redigo
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+// multiLockScript sets all the keys to ARGV[1] with an expiry of ARGV[2] milliseconds
+// if none of them exists. It returns 1 if the keys were locked, 0 otherwise
+var multiLockScript = NewScript(-1, `
+	for _, key in ipairs(KEYS) do
+		if redis.call("EXISTS", key) == 1 then
+			return 0
+		end
+	end
+	for _, key in ipairs(KEYS) do
+		redis.call("SET", key, ARGV[1], "PX", ARGV[2])
+	end
+	return 1
+`)
+
+// multiUnlockScript deletes the keys still held by ARGV[1] and publishes each of them to its
+// release channel, ARGV[2] followed by the key. It returns the number of released keys
+var multiUnlockScript = NewScript(-1, `
+	local released = 0
+	for _, key in ipairs(KEYS) do
+		if redis.call("GET", key) == ARGV[1] then
+			redis.call("DEL", key)
+			redis.call("PUBLISH", ARGV[2] .. key, key)
+			released = released + 1
+		end
+	end
+	return released
+`)
+
+// multiExtendScript resets the expiry of all the keys to ARGV[2] milliseconds if they are all
+// still held by ARGV[1]. It returns 1 if the keys were extended, 0 otherwise
+var multiExtendScript = NewScript(-1, `
+	for _, key in ipairs(KEYS) do
+		if redis.call("GET", key) ~= ARGV[1] then
+			return 0
+		end
+	end
+	for _, key in ipairs(KEYS) do
+		redis.call("PEXPIRE", key, ARGV[2])
+	end
+	return 1
+`)
+
+// MultiLock is a distributed lock over several keys acquired all-or-nothing
+type MultiLock struct {
+	keys   []string
+	value  string
+	redigo *Redigo
+
+	locker   sync.Mutex
+	acquired bool
+}
+
+// MultiLock acquires the locks of all keys atomically, either all of them or none, so locking several
+// resources cannot deadlock whatever the order of the keys. It retries like LockContext while any of
+// the keys is held, until ctx is done or WithMaxAttempts is reached, in which case an error wrapping
+// ErrLockAcquisitionFailed is returned. expiry overrides WithLockExpiry. The duplicate keys are locked
+// once, ErrNoLockKeys is returned if keys is empty
+func (r *Redigo) MultiLock(ctx context.Context, keys []string, expiry time.Duration, opts ...LockOption) (*MultiLock, error) {
+	keys = uniqueKeys(keys)
+	if len(keys) == 0 {
+		return nil, ErrNoLockKeys
+	}
+	options := parseLockOptions(append(slices.Clip(opts), WithLockExpiry(expiry))...)
+	value, err := newLockValue(options)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.acquireKeys(ctx, keys, options, func(ctx context.Context) (bool, error) {
+		result, err := r.evalScriptContext(ctx, multiLockScript, multiLockArgs(keys, value, options.expiry.Milliseconds())...)
+		return result == int64(1), err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &MultiLock{
+		keys:     keys,
+		value:    value,
+		redigo:   r,
+		acquired: true,
+	}, nil
+}
+
+// uniqueKeys 返回去重后的key副本, 保持原有顺序
+func uniqueKeys(keys []string) []string {
+	var seen = make(map[string]struct{}, len(keys))
+	var unique = make([]string, 0, len(keys))
+	for _, key := range keys {
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		unique = append(unique, key)
+	}
+	return unique
+}
+
+// multiLockArgs 构造 numkeys key1 key2 ... arg1 arg2 ... 参数
+func multiLockArgs(keys []string, args ...any) []any {
+	var keysAndArgs = make([]any, 0, len(keys)+len(args)+1)
+	keysAndArgs = append(keysAndArgs, len(keys))
+	for _, key := range keys {
+		keysAndArgs = append(keysAndArgs, key)
+	}
+	return append(keysAndArgs, args...)
+}
+
+// Keys returns the keys of the lock
+func (l *MultiLock) Keys() []string {
+	return append([]string(nil), l.keys...)
+}
+
+// Value returns the random value identifying the owner of the lock
+func (l *MultiLock) Value() string {
+	return l.value
+}
+
+// Unlock releases all the keys still held, it returns ErrLockNotHeld if any of them had expired
+func (l *MultiLock) Unlock() error {
+	l.locker.Lock()
+	defer l.locker.Unlock()
+	if !l.acquired {
+		return ErrLockNotHeld
+	}
+
+	result, err := l.redigo.evalScript(multiUnlockScript, multiLockArgs(l.keys, l.value, lockChannelPrefix)...)
+	if err != nil {
+		return err
+	}
+	l.acquired = false
+	if result != int64(len(l.keys)) {
+		return ErrLockNotHeld
+	}
+	return nil
+}
+
+// Extend resets the expiry of all the keys to d from now, it returns ErrLockNotHeld
+// and extends none of them if any of them has expired or is held by someone else
+func (l *MultiLock) Extend(d time.Duration) error {
+	l.locker.Lock()
+	defer l.locker.Unlock()
+	if !l.acquired {
+		return ErrLockNotHeld
+	}
+
+	result, err := l.redigo.evalScript(multiExtendScript, multiLockArgs(l.keys, l.value, d.Milliseconds())...)
+	if err != nil {
+		return err
+	}
+	if result == int64(0) {
+		return ErrLockNotHeld
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package redigo
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRedigo_MultiLock(t *testing.T) {
+	redigo := NewRedigo(opts...)
+	keys := []string{"test_multi_lock_a", "test_multi_lock_b"}
+	_, _ = redigo.Del(keys...)
+
+	lock, err := redigo.MultiLock(context.Background(), keys, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := redigo.Exists(keys...); n != 2 {
+		t.Fatalf("expected 2 keys locked, got %d", n)
+	}
+
+	// 任一key被持有时整体失败, 且不会留下部分锁
+	_, err = redigo.MultiLock(context.Background(), []string{keys[1], "test_multi_lock_c"}, 5*time.Second, WithMaxAttempts(2))
+	if !errors.Is(err, ErrLockAcquisitionFailed) {
+		t.Fatalf("expected ErrLockAcquisitionFailed, got %v", err)
+	}
+	if n, _ := redigo.Exists("test_multi_lock_c"); n != 0 {
+		t.Fatal("expected no partial lock")
+	}
+
+	if err = lock.Extend(10 * time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if ttl, _ := redigo.PTTL(keys[0]); ttl <= 5*time.Second {
+		t.Fatalf("expected extended ttl, got %v", ttl)
+	}
+
+	// 释放时通知等待者
+	go func() {
+		time.Sleep(200 * time.Millisecond)
+		_ = lock.Unlock()
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	other, err := redigo.MultiLock(ctx, []string{keys[1], keys[0]}, 5*time.Second, WithRetryStrategy(FixedRetry(time.Minute)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = lock.Extend(time.Second); !errors.Is(err, ErrLockNotHeld) {
+		t.Fatalf("expected ErrLockNotHeld, got %v", err)
+	}
+	if err = other.Unlock(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRedigo_MultiLockKeys(t *testing.T) {
+	redigo := NewRedigo(opts...)
+	if _, err := redigo.MultiLock(context.Background(), nil, 5*time.Second); !errors.Is(err, ErrNoLockKeys) {
+		t.Fatalf("expected ErrNoLockKeys, got %v", err)
+	}
+
+	// 重复的key只加锁一次, 修改调用方的切片不影响锁
+	keys := []string{"test_multi_lock_a", "test_multi_lock_b", "test_multi_lock_a"}
+	_, _ = redigo.Del(keys...)
+	lock, err := redigo.MultiLock(context.Background(), keys, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys[1] = "test_multi_lock_c"
+	if got := lock.Keys(); len(got) != 2 || got[1] != "test_multi_lock_b" {
+		t.Fatalf("unexpected keys %v", got)
+	}
+	if err = lock.Unlock(); err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := redigo.Exists("test_multi_lock_a", "test_multi_lock_b"); n != 0 {
+		t.Fatalf("expected keys released, %d left", n)
+	}
+}
+
+func TestRedigo_MultiLockKeepsOptions(t *testing.T) {
+	redigo := NewRedigo(opts...)
+	keys := []string{"test_multi_lock_a", "test_multi_lock_b"}
+	_, _ = redigo.Del(keys...)
+
+	// 调用方切片的剩余容量不会被写入
+	lockOpts := make([]LockOption, 1, 4)
+	lockOpts[0] = WithMaxAttempts(1)
+	lock, err := redigo.MultiLock(context.Background(), keys, 5*time.Second, lockOpts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lock.Unlock()
+	if lockOpts[:2][1] != nil {
+		t.Fatal("MultiLock wrote into the spare capacity of the options")
+	}
+}
@@ -0,0 +1,133 @@
+package redigo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Mutex adapts a distributed lock to sync.Locker. The lease is kept alive by a watchdog while the
+// mutex is locked. Since Lock and Unlock cannot return errors, Lock retries until it holds the lock
+// and the failures of TryLock and Unlock panic unless a handler is set with WithErrorHandler
+type Mutex struct {
+	key     string
+	redigo  *Redigo
+	opts    []LockOption
+	retry   RetryStrategy
+	onError func(err error)
+
+	locker   sync.Mutex
+	lock     *Lock
+	released chan struct{} // closed when the held lock is unlocked
+}
+
+var _ sync.Locker = (*Mutex)(nil)
+
+// Mutex returns the mutex of key, the lock is acquired like LockContext with opts and a watchdog
+// (see WithWatchdog, renewing every third of the expiry by default)
+func (r *Redigo) Mutex(key string, opts ...LockOption) *Mutex {
+	options := parseLockOptions(opts...)
+	if !options.watchdog {
+		opts = append(opts, WithWatchdog(0))
+	}
+	// 截断容量, 并发的TryLock追加选项时各自复制, 不会写入同一个底层数组
+	opts = slices.Clip(opts)
+	return &Mutex{
+		key:     key,
+		redigo:  r,
+		opts:    opts,
+		retry:   options.retry,
+		onError: options.onError,
+	}
+}
+
+// Key returns the key of the mutex
+func (m *Mutex) Key() string {
+	return m.key
+}
+
+// Lock blocks until the lock is acquired, retrying according to the options. It never returns
+// without holding the lock: the failures, e.g. when WithMaxAttempts is reached or on network errors,
+// are passed to the error handler if any and the acquisition is retried after a backoff
+func (m *Mutex) Lock() {
+	for failures := 1; ; failures++ {
+		lock, err := m.redigo.LockContext(context.Background(), m.key, m.opts...)
+		if err == nil {
+			m.hold(lock)
+			return
+		}
+		if m.onError != nil {
+			m.onError(fmt.Errorf("lock %s: %w", m.key, err))
+		}
+		time.Sleep(m.backoff(failures))
+	}
+}
+
+// backoff 返回获取失败后再次尝试前的等待时间, 重试策略不再重试时使用最大退避时间
+func (m *Mutex) backoff(failures int) time.Duration {
+	if backoff, ok := m.retry.NextBackoff(failures); ok {
+		return backoff
+	}
+	return defaultLockMaxBackoff
+}
+
+// TryLock tries to acquire the lock once and reports whether it succeeded,
+// errors other than a held lock panic or are passed to the error handler
+func (m *Mutex) TryLock() bool {
+	lock, err := m.redigo.LockContext(context.Background(), m.key, append(m.opts, WithMaxAttempts(1))...)
+	if err != nil {
+		if !errors.Is(err, ErrLockAcquisitionFailed) {
+			m.fail(fmt.Errorf("lock %s: %w", m.key, err))
+		}
+		return false
+	}
+	m.hold(lock)
+	return true
+}
+
+// Unlock releases the lock, unlocking a mutex which is not locked or whose lease was lost
+// panics or passes ErrLockNotHeld to the error handler
+func (m *Mutex) Unlock() {
+	m.locker.Lock()
+	lock, released := m.lock, m.released
+	m.lock, m.released = nil, nil
+	m.locker.Unlock()
+
+	if lock == nil {
+		m.fail(fmt.Errorf("unlock %s: %w", m.key, ErrLockNotHeld))
+		return
+	}
+	close(released)
+	if err := lock.Unlock(); err != nil {
+		m.fail(fmt.Errorf("unlock %s: %w", m.key, err))
+	}
+}
+
+// hold 记录持有的锁, 续期失败时通知错误处理函数
+func (m *Mutex) hold(lock *Lock) {
+	released := make(chan struct{})
+	m.locker.Lock()
+	m.lock, m.released = lock, released
+	m.locker.Unlock()
+
+	if m.onError != nil {
+		go func() {
+			select {
+			case <-lock.Lost():
+				m.onError(fmt.Errorf("lock %s: %w", m.key, ErrLockLost))
+			case <-released:
+			}
+		}()
+	}
+}
+
+// fail 调用错误处理函数, 未设置时panic
+func (m *Mutex) fail(err error) {
+	if m.onError == nil {
+		panic(err)
+	}
+	m.onError(err)
+}
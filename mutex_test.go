@@ -0,0 +1,144 @@
+package redigo
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const (
+	testMutexKey = "test_mutex_key"
+)
+
+func TestRedigo_Mutex(t *testing.T) {
+	redigo := NewRedigo(opts...)
+	_, _ = redigo.Del(testMutexKey)
+
+	var locker sync.Locker = redigo.Mutex(testMutexKey, WithLockExpiry(300*time.Millisecond))
+	var wg sync.WaitGroup
+	var counter int
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			locker.Lock()
+			defer locker.Unlock()
+			// 持有期间看门狗续期, 超过租期仍然互斥
+			n := counter
+			time.Sleep(50 * time.Millisecond)
+			counter = n + 1
+		}()
+	}
+	wg.Wait()
+	if counter != 5 {
+		t.Fatalf("expected 5, got %d", counter)
+	}
+
+	m := redigo.Mutex(testMutexKey, WithLockExpiry(5*time.Second))
+	m.Lock()
+	time.Sleep(400 * time.Millisecond)
+	if m.TryLock() {
+		t.Fatal("expected TryLock to fail while locked")
+	}
+	m.Unlock()
+	if !m.TryLock() {
+		t.Fatal("expected TryLock to succeed")
+	}
+	m.Unlock()
+}
+
+func TestRedigo_MutexErrorHandler(t *testing.T) {
+	redigo := NewRedigo(opts...)
+	_, _ = redigo.Del(testMutexKey)
+
+	// 默认panic
+	func() {
+		defer func() {
+			if err, ok := recover().(error); !ok || !errors.Is(err, ErrLockNotHeld) {
+				t.Fatalf("expected panic with ErrLockNotHeld, got %v", err)
+			}
+		}()
+		redigo.Mutex(testMutexKey).Unlock()
+	}()
+
+	var errs []error
+	m := redigo.Mutex(testMutexKey, WithErrorHandler(func(err error) {
+		errs = append(errs, err)
+	}))
+	m.Unlock()
+	if len(errs) != 1 || !errors.Is(errs[0], ErrLockNotHeld) {
+		t.Fatalf("expected ErrLockNotHeld, got %v", errs)
+	}
+}
+
+func TestRedigo_MutexLockRetries(t *testing.T) {
+	redigo := NewRedigo(opts...)
+	_, _ = redigo.Del(testMutexKey)
+
+	lock, err := redigo.LockContext(context.Background(), testMutexKey, WithLockExpiry(5*time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.AfterFunc(300*time.Millisecond, func() {
+		_ = lock.Unlock()
+	})
+
+	// 每次获取失败都报告给错误处理函数, Lock返回时一定持有锁
+	var failures atomic.Int64
+	m := redigo.Mutex(testMutexKey, WithMaxAttempts(1), WithRetryStrategy(FixedRetry(50*time.Millisecond)),
+		WithErrorHandler(func(err error) {
+			if !errors.Is(err, ErrLockAcquisitionFailed) {
+				t.Errorf("expected ErrLockAcquisitionFailed, got %v", err)
+			}
+			failures.Add(1)
+		}))
+	m.Lock()
+	if failures.Load() == 0 {
+		t.Fatal("expected failures reported while the lock was held")
+	}
+	if info, err := redigo.GetLockInfo(testMutexKey); err != nil || info.Value == lock.Value() {
+		t.Fatalf("expected the mutex to hold the lock, got %+v (%v)", info, err)
+	}
+	m.Unlock()
+
+	// 没有错误处理函数时不会panic
+	lock, err = redigo.LockContext(context.Background(), testMutexKey, WithLockExpiry(5*time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.AfterFunc(200*time.Millisecond, func() {
+		_ = lock.Unlock()
+	})
+	m = redigo.Mutex(testMutexKey, WithMaxAttempts(1), WithRetryStrategy(FixedRetry(50*time.Millisecond)))
+	m.Lock()
+	m.Unlock()
+}
+
+func TestRedigo_MutexConcurrentTryLock(t *testing.T) {
+	redigo := NewRedigo(opts...)
+	_, _ = redigo.Del(testMutexKey)
+
+	// 选项切片有剩余容量时并发TryLock不会相互覆盖选项
+	lockOpts := make([]LockOption, 1, 4)
+	lockOpts[0] = WithLockExpiry(5 * time.Second)
+	m := redigo.Mutex(testMutexKey, lockOpts...)
+	var wg sync.WaitGroup
+	var acquired atomic.Int64
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if m.TryLock() {
+				acquired.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	if acquired.Load() != 1 {
+		t.Fatalf("expected one TryLock to succeed, got %d", acquired.Load())
+	}
+	m.Unlock()
+}
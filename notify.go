@@ -0,0 +1,224 @@
+package redigo
+
+import (
+	"context"
+	"github.com/gomodule/redigo/redis"
+	"sync"
+	"time"
+)
+
+const (
+	lockChannelPrefix = "redigo:unlock:"
+)
+
+// lockChannel returns the channel the release of the lock key is published to
+func lockChannel(key string) string {
+	return lockChannelPrefix + key
+}
+
+// unlockNotifier shares a single PubSub connection, dialed outside the pool, between all the waiters
+// of a client and fans the release notifications out to the waiters subscribed to each channel
+type unlockNotifier struct {
+	dial func(ctx context.Context) (redis.Conn, error)
+
+	locker   sync.Mutex
+	psc      *redis.PubSubConn
+	channels map[string]*unlockChannel
+	pending  map[string]int // SUBSCRIBE sent and not confirmed yet, by channel
+}
+
+// unlockChannel 订阅同一频道的等待者, 订阅确认后关闭ready
+type unlockChannel struct {
+	waiters map[*unlockWaiter]struct{}
+	ready   chan struct{}
+	closed  bool
+}
+
+// unlockWaiter is notified when the lock of any of its channels is released
+type unlockWaiter struct {
+	notifier *unlockNotifier
+	channels []string
+	notify   chan struct{}
+}
+
+func newUnlockNotifier(dial func(ctx context.Context) (redis.Conn, error)) *unlockNotifier {
+	return &unlockNotifier{
+		dial:     dial,
+		channels: make(map[string]*unlockChannel),
+		pending:  make(map[string]int),
+	}
+}
+
+// subscribeUnlock subscribes to the release channels of keys, the waiter is notified when any of them is released.
+// It returns once the subscriptions are confirmed by the server, so the releases after it are not missed
+func (r *Redigo) subscribeUnlock(ctx context.Context, keys ...string) (*unlockWaiter, error) {
+	w := &unlockWaiter{
+		notifier: r.notifier,
+		channels: make([]string, 0, len(keys)),
+		notify:   make(chan struct{}, 1),
+	}
+	for _, key := range keys {
+		w.channels = append(w.channels, lockChannel(key))
+	}
+
+	ready, err := r.notifier.subscribe(ctx, w)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range ready {
+		select {
+		case <-c:
+		case <-ctx.Done():
+			w.close()
+			return nil, ctx.Err()
+		}
+	}
+	return w, nil
+}
+
+// subscribe 注册等待者, 新频道发送SUBSCRIBE, 返回各频道的订阅确认通道
+func (n *unlockNotifier) subscribe(ctx context.Context, w *unlockWaiter) ([]chan struct{}, error) {
+	n.locker.Lock()
+	defer n.locker.Unlock()
+	if n.psc == nil {
+		conn, err := n.dial(ctx)
+		if err != nil {
+			return nil, err
+		}
+		n.psc = &redis.PubSubConn{Conn: conn}
+		go n.receive(n.psc)
+	}
+
+	var subscribe []any
+	var ready = make([]chan struct{}, 0, len(w.channels))
+	for _, channel := range w.channels {
+		c, ok := n.channels[channel]
+		if !ok {
+			c = &unlockChannel{
+				waiters: make(map[*unlockWaiter]struct{}),
+				ready:   make(chan struct{}),
+			}
+			n.channels[channel] = c
+			n.pending[channel]++
+			subscribe = append(subscribe, channel)
+		}
+		c.waiters[w] = struct{}{}
+		ready = append(ready, c.ready)
+	}
+	if len(subscribe) > 0 {
+		if err := n.psc.Subscribe(subscribe...); err != nil {
+			n.remove(w)
+			return nil, err
+		}
+	}
+	return ready, nil
+}
+
+// remove 移除等待者, 没有等待者的频道取消订阅
+func (n *unlockNotifier) remove(w *unlockWaiter) {
+	var unsubscribe []any
+	for _, channel := range w.channels {
+		c, ok := n.channels[channel]
+		if !ok {
+			continue
+		}
+		delete(c.waiters, w)
+		if len(c.waiters) == 0 {
+			delete(n.channels, channel)
+			unsubscribe = append(unsubscribe, channel)
+		}
+	}
+	if len(unsubscribe) > 0 && n.psc != nil {
+		_ = n.psc.Unsubscribe(unsubscribe...)
+	}
+}
+
+// receive 分发释放通知直到连接出错或不再有订阅, 连接出错时唤醒所有等待者, 之后等待退化为轮询
+func (n *unlockNotifier) receive(psc *redis.PubSubConn) {
+	for {
+		switch v := psc.Receive().(type) {
+		case redis.Message:
+			n.locker.Lock()
+			if c, ok := n.channels[v.Channel]; ok {
+				for w := range c.waiters {
+					w.wake()
+				}
+			}
+			n.locker.Unlock()
+		case redis.Subscription:
+			n.locker.Lock()
+			switch v.Kind {
+			case "subscribe":
+				if n.pending[v.Channel]--; n.pending[v.Channel] <= 0 {
+					delete(n.pending, v.Channel)
+					if c, ok := n.channels[v.Channel]; ok && !c.closed {
+						c.closed = true
+						close(c.ready)
+					}
+				}
+			case "unsubscribe":
+				// 没有订阅时关闭连接, 下次订阅时重新建立
+				if v.Count == 0 && len(n.channels) == 0 && len(n.pending) == 0 {
+					n.reset(psc)
+					n.locker.Unlock()
+					return
+				}
+			}
+			n.locker.Unlock()
+		case error:
+			n.locker.Lock()
+			n.reset(psc)
+			for channel, c := range n.channels {
+				for w := range c.waiters {
+					w.wake()
+				}
+				if !c.closed {
+					c.closed = true
+					close(c.ready)
+				}
+				delete(n.channels, channel)
+			}
+			n.locker.Unlock()
+			return
+		}
+	}
+}
+
+// reset 关闭连接, 调用时需持有锁
+func (n *unlockNotifier) reset(psc *redis.PubSubConn) {
+	_ = psc.Close()
+	if n.psc == psc {
+		n.psc = nil
+		clear(n.pending)
+	}
+}
+
+func (w *unlockWaiter) wake() {
+	select {
+	case w.notify <- struct{}{}:
+	default:
+	}
+}
+
+// wait blocks until the lock is released, backoff elapsed or ctx is done, a nil waiter only sleeps
+func (w *unlockWaiter) wait(ctx context.Context, backoff time.Duration) error {
+	var notify <-chan struct{}
+	if w != nil {
+		notify = w.notify
+	}
+	timer := time.NewTimer(backoff)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+	case <-notify:
+	}
+	return nil
+}
+
+func (w *unlockWaiter) close() {
+	w.notifier.locker.Lock()
+	defer w.notifier.locker.Unlock()
+	w.notifier.remove(w)
+}
@@ -1,8 +1,10 @@
 package redigo
 
 import (
+	"context"
 	"crypto/tls"
 	"fmt"
+	"io/fs"
 	"time"
 )
 
@@ -52,6 +54,28 @@ type redigoOptions struct {
 	useTLS     bool
 	skipVerify bool
 	tlsConfig  *tls.Config
+
+	// Lua scripts loaded on every new connection
+	scripts []*Script
+
+	// function libraries loaded by NewRedigo
+	functionLibraries []functionLibrary
+
+	// codec of the values which are not of a basic type, JSON by default
+	codec Codec
+
+	// compressor of the values of at least compressThreshold bytes, no compression if nil
+	compressor        Compressor
+	compressThreshold int
+
+	// keyring of the envelope encryption, no encryption if nil
+	keyring       *Keyring
+	deterministic bool
+}
+
+type functionLibrary struct {
+	fsys fs.FS
+	path string
 }
 
 func WithAddress(address string) Option {
@@ -132,6 +156,48 @@ func WithMaxConnLifetime(maxConnLifetime time.Duration) Option {
 	}
 }
 
+// WithScripts register Lua scripts loaded by SCRIPT LOAD on every new connection
+func WithScripts(scripts ...*Script) Option {
+	return func(o *redigoOptions) {
+		o.scripts = append(o.scripts, scripts...)
+	}
+}
+
+// WithFunctionLibrary load the function library stored in file path of fsys (e.g. an embed.FS)
+// with FUNCTION LOAD REPLACE when NewRedigo is called
+func WithFunctionLibrary(fsys fs.FS, path string) Option {
+	return func(o *redigoOptions) {
+		o.functionLibraries = append(o.functionLibraries, functionLibrary{fsys: fsys, path: path})
+	}
+}
+
+// WithCodec set the codec of the values which are not of a basic type, JSON by default.
+// A single call can use another codec with Redigo.WithCodec
+func WithCodec(c Codec) Option {
+	return func(o *redigoOptions) {
+		o.codec = c
+	}
+}
+
+// WithCompression compress with c the strings, byte slices and encoded values of at least threshold bytes,
+// e.g. WithCompression(GzipCompressor(gzip.DefaultCompression), 1024). The compressed values carry a
+// header so they are decompressed automatically on read, uncompressed values remain readable
+func WithCompression(c Compressor, threshold int) Option {
+	return func(o *redigoOptions) {
+		o.compressor = c
+		o.compressThreshold = threshold
+	}
+}
+
+// WithEncryption encrypt the strings, byte slices and encoded values with AES-GCM using the primary key
+// of keyring. The encrypted values carry the ID of their key, they are decrypted automatically on read
+// with any key of the ring, unencrypted values remain readable
+func WithEncryption(keyring *Keyring) Option {
+	return func(o *redigoOptions) {
+		o.keyring = keyring
+	}
+}
+
 func checkParams(o *redigoOptions) error {
 	if o.address == "" {
 		return fmt.Errorf("empty redis address")
@@ -145,10 +211,14 @@ func checkParams(o *redigoOptions) error {
 /*--------------------------------------------------------------------------------------------------------------------*/
 
 type setOptions struct {
-	ex int64 //EX expire in seconds
-	nx bool  //NX only do when the key not exist
-	xx bool  //XX only do when the key exist
-	px int64 //PX expire in milliseconds
+	ex      int64 //EX expire in seconds
+	nx      bool  //NX only do when the key not exist
+	xx      bool  //XX only do when the key exist
+	px      int64 //PX expire in milliseconds
+	exat    int64 //EXAT expire at unix time in seconds
+	pxat    int64 //PXAT expire at unix time in milliseconds
+	keepTTL bool  //KEEPTTL retain the time to live of the key
+	get     any   //GET decode the old value into get
 }
 
 type SetOption func(*setOptions)
@@ -189,6 +259,46 @@ func WithPX(px int64) SetOption {
 	}
 }
 
+// WithExpiration set the expiry by a duration, PX is used when it is not a whole number of seconds
+func WithExpiration(expiration time.Duration) SetOption {
+	return func(o *setOptions) {
+		if expiration%time.Second == 0 {
+			o.ex = int64(expiration / time.Second)
+		} else {
+			o.px = expiration.Milliseconds()
+		}
+	}
+}
+
+// WithEXAT set EXAT option
+func WithEXAT(tm time.Time) SetOption {
+	return func(o *setOptions) {
+		o.exat = tm.Unix()
+	}
+}
+
+// WithPXAT set PXAT option
+func WithPXAT(tm time.Time) SetOption {
+	return func(o *setOptions) {
+		o.pxat = tm.UnixMilli()
+	}
+}
+
+// WithKeepTTL set KEEPTTL option
+func WithKeepTTL() SetOption {
+	return func(o *setOptions) {
+		o.keepTTL = true
+	}
+}
+
+// WithGET set GET option, the old value is decoded into old (a non-nil pointer) like Get
+// and old is left untouched if the key did not exist
+func WithGET(old any) SetOption {
+	return func(o *setOptions) {
+		o.get = old
+	}
+}
+
 /*--------------------------------------------------------------------------------------------------------------------*/
 
 type ListOption func(*listOptions)
@@ -213,6 +323,7 @@ func WithRright() ListOption {
 	}
 }
 
+// WithBlock use the blocking pop commands (BLPOP/BRPOP) in ListPop, it is ignored by ListPush
 func WithBlock() ListOption {
 	return func(o *listOptions) {
 		o.block = true
@@ -223,3 +334,383 @@ func WithUnwind() ListOption {
 		o.unwind = true
 	}
 }
+
+/*--------------------------------------------------------------------------------------------------------------------*/
+
+const (
+	defaultTxRetries = 10
+)
+
+type TxOption func(*txOptions)
+
+type txOptions struct {
+	retries int // max attempts of Watch when EXEC is aborted
+}
+
+func parseTxOptions(opts ...TxOption) *txOptions {
+	options := &txOptions{
+		retries: defaultTxRetries,
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+	return options
+}
+
+// WithTxRetries set max attempts of Watch when the watched keys changed
+func WithTxRetries(retries int) TxOption {
+	return func(o *txOptions) {
+		o.retries = retries
+	}
+}
+
+/*--------------------------------------------------------------------------------------------------------------------*/
+
+type RestorePolicy string
+
+const (
+	RestorePolicyAppend  RestorePolicy = "APPEND"
+	RestorePolicyReplace RestorePolicy = "REPLACE"
+	RestorePolicyFlush   RestorePolicy = "FLUSH"
+)
+
+type FunctionOption func(*functionOptions)
+
+type functionOptions struct {
+	replace       bool          // FUNCTION LOAD REPLACE
+	libraryName   string        // FUNCTION LIST LIBRARYNAME pattern
+	withCode      bool          // FUNCTION LIST WITHCODE
+	restorePolicy RestorePolicy // FUNCTION RESTORE policy
+}
+
+func parseFunctionOptions(opts ...FunctionOption) *functionOptions {
+	options := &functionOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+	return options
+}
+
+// WithFunctionReplace replace the library if it already exists
+func WithFunctionReplace() FunctionOption {
+	return func(o *functionOptions) {
+		o.replace = true
+	}
+}
+
+// WithLibraryName only list the libraries whose name matches the pattern
+func WithLibraryName(pattern string) FunctionOption {
+	return func(o *functionOptions) {
+		o.libraryName = pattern
+	}
+}
+
+// WithLibraryCode list the libraries with their source code
+func WithLibraryCode() FunctionOption {
+	return func(o *functionOptions) {
+		o.withCode = true
+	}
+}
+
+// WithRestorePolicy set the policy of FUNCTION RESTORE
+func WithRestorePolicy(policy RestorePolicy) FunctionOption {
+	return func(o *functionOptions) {
+		o.restorePolicy = policy
+	}
+}
+
+/*--------------------------------------------------------------------------------------------------------------------*/
+
+type ExpireOption func(*expireOptions)
+
+type expireOptions struct {
+	condition string // NX/XX/GT/LT
+}
+
+func parseExpireOptions(opts ...ExpireOption) *expireOptions {
+	options := &expireOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+	return options
+}
+
+// WithExpireNX set the expiry only when the key has no expiry
+func WithExpireNX() ExpireOption {
+	return func(o *expireOptions) {
+		o.condition = "NX"
+	}
+}
+
+// WithExpireXX set the expiry only when the key has an existing expiry
+func WithExpireXX() ExpireOption {
+	return func(o *expireOptions) {
+		o.condition = "XX"
+	}
+}
+
+// WithExpireGT set the expiry only when the new expiry is greater than current one
+func WithExpireGT() ExpireOption {
+	return func(o *expireOptions) {
+		o.condition = "GT"
+	}
+}
+
+// WithExpireLT set the expiry only when the new expiry is less than current one
+func WithExpireLT() ExpireOption {
+	return func(o *expireOptions) {
+		o.condition = "LT"
+	}
+}
+
+/*--------------------------------------------------------------------------------------------------------------------*/
+
+type CopyOption func(*copyOptions)
+
+type copyOptions struct {
+	db      *int // DB destination db
+	replace bool // REPLACE remove the destination key before copying
+}
+
+func parseCopyOptions(opts ...CopyOption) *copyOptions {
+	options := &copyOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+	return options
+}
+
+// WithCopyDB copy the value to another db
+func WithCopyDB(db int) CopyOption {
+	return func(o *copyOptions) {
+		o.db = &db
+	}
+}
+
+// WithCopyReplace remove the destination key before copying
+func WithCopyReplace() CopyOption {
+	return func(o *copyOptions) {
+		o.replace = true
+	}
+}
+
+/*--------------------------------------------------------------------------------------------------------------------*/
+
+type CounterOption func(*counterOptions)
+
+type counterOptions struct {
+	expiry time.Duration // expiry set when the counter is created
+	max    *int64        // upper bound of the counter
+	min    *int64        // lower bound of the counter
+}
+
+func parseCounterOptions(opts ...CounterOption) *counterOptions {
+	options := &counterOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+	return options
+}
+
+// WithCounterExpiry set the expiry of the counter when it is created by an increment
+func WithCounterExpiry(expiry time.Duration) CounterOption {
+	return func(o *counterOptions) {
+		o.expiry = expiry
+	}
+}
+
+// WithCounterMax fail the increments which would make the counter greater than max
+func WithCounterMax(max int64) CounterOption {
+	return func(o *counterOptions) {
+		o.max = &max
+	}
+}
+
+// WithCounterMin fail the decrements which would make the counter less than min
+func WithCounterMin(min int64) CounterOption {
+	return func(o *counterOptions) {
+		o.min = &min
+	}
+}
+
+/*--------------------------------------------------------------------------------------------------------------------*/
+
+const (
+	defaultLockExpiry     = 30 * time.Second
+	defaultLockMinBackoff = 16 * time.Millisecond
+	defaultLockMaxBackoff = time.Second
+	defaultWaiterTimeout  = 5 * time.Second
+)
+
+type LockOption func(*lockOptions)
+
+type lockOptions struct {
+	expiry           time.Duration // lease of the lock
+	retry            RetryStrategy // delay between attempts
+	maxAttempts      int           // max attempts of acquiring, 0 means no limit
+	notify           bool          // wait for the release notification between attempts
+	watchdog         bool          // renew the lease in the background until Unlock
+	watchdogInterval time.Duration // renew interval, a third of the expiry by default
+	waiterTimeout    time.Duration // queue entry lifetime of a fair lock waiter
+	metadata         bool          // store the owner metadata in the lock value
+	purpose          string        // purpose stored in the owner metadata
+	onError          func(error)   // failure handler of Mutex, panic if nil
+	fencing          bool          // increment the fencing counter of the lock on every acquisition
+}
+
+func parseLockOptions(opts ...LockOption) *lockOptions {
+	options := &lockOptions{
+		expiry:        defaultLockExpiry,
+		retry:         ExponentialBackoff(defaultLockMinBackoff, defaultLockMaxBackoff),
+		notify:        true,
+		waiterTimeout: defaultWaiterTimeout,
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+	return options
+}
+
+// WithLockExpiry set the lease of the lock, 30 seconds by default
+func WithLockExpiry(expiry time.Duration) LockOption {
+	return func(o *lockOptions) {
+		o.expiry = expiry
+	}
+}
+
+// WithRetryStrategy set the delay between attempts, exponential backoff with jitter by default
+func WithRetryStrategy(retry RetryStrategy) LockOption {
+	return func(o *lockOptions) {
+		o.retry = retry
+	}
+}
+
+// WithMaxAttempts limit the number of attempts of acquiring, including the first one
+func WithMaxAttempts(maxAttempts int) LockOption {
+	return func(o *lockOptions) {
+		o.maxAttempts = maxAttempts
+	}
+}
+
+// WithLockNotify enable or disable waiting for the release notification (enabled by default).
+// When enabled a waiter subscribes to the release channel of the lock after the first failed attempt
+// and retries as soon as the lock is released, the retry strategy is still used as a polling fallback
+func WithLockNotify(notify bool) LockOption {
+	return func(o *lockOptions) {
+		o.notify = notify
+	}
+}
+
+// WithFencing issue a fencing token (see Lock.Token) on every acquisition of the lock. The tokens
+// come from a counter stored in "<key>:fencing" which never expires, so that it keeps increasing
+// across the holders; without this option the lock is a plain SET NX PX and leaves no key behind
+func WithFencing() LockOption {
+	return func(o *lockOptions) {
+		o.fencing = true
+	}
+}
+
+// WithWatchdog renew the lease every interval in the background until Unlock,
+// a third of the expiry is used if interval is not positive, and then follows the expiry set by Extend
+func WithWatchdog(interval time.Duration) LockOption {
+	return func(o *lockOptions) {
+		o.watchdog = true
+		o.watchdogInterval = interval
+	}
+}
+
+// WithLockMetadata store the owner metadata (host, pid, acquisition time and purpose) as JSON in the
+// lock value so the holder of a stuck lock can be found with GetLockInfo or ListLocks
+func WithLockMetadata(purpose string) LockOption {
+	return func(o *lockOptions) {
+		o.metadata = true
+		o.purpose = purpose
+	}
+}
+
+// WithErrorHandler set the handler of the failures of a Mutex, which panics by default because
+// sync.Locker cannot return errors. Lock keeps retrying and reports each failed acquisition to it.
+// The handler is also called with ErrLockLost when the watchdog fails to renew the lease while the
+// mutex is locked
+func WithErrorHandler(fn func(err error)) LockOption {
+	return func(o *lockOptions) {
+		o.onError = fn
+	}
+}
+
+// WithWaiterTimeout set how long the queue entry of a fair lock waiter lives without being refreshed,
+// 5 seconds by default. A waiter refreshes its entry on every attempt so the timeout must be longer
+// than the retry backoff, the entries of abandoned waiters are removed once they timed out
+func WithWaiterTimeout(timeout time.Duration) LockOption {
+	return func(o *lockOptions) {
+		o.waiterTimeout = timeout
+	}
+}
+
+/*--------------------------------------------------------------------------------------------------------------------*/
+
+const (
+	defaultLeaseDuration = 15 * time.Second
+	defaultRenewDeadline = 10 * time.Second
+	defaultRetryPeriod   = 2 * time.Second
+)
+
+type ElectorOption func(*electorOptions)
+
+type electorOptions struct {
+	leaseDuration    time.Duration             // lease of the leadership
+	renewDeadline    time.Duration             // time the leader keeps trying to renew before stepping down
+	retryPeriod      time.Duration             // interval of the campaign attempts and of the renewals
+	onStartedLeading func(ctx context.Context) // called when the leadership is acquired
+	onStoppedLeading func()                    // called when the leadership is lost
+}
+
+func parseElectorOptions(opts ...ElectorOption) *electorOptions {
+	options := &electorOptions{
+		leaseDuration: defaultLeaseDuration,
+		renewDeadline: defaultRenewDeadline,
+		retryPeriod:   defaultRetryPeriod,
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+	return options
+}
+
+// WithLeaseDuration set the lease of the leadership, 15 seconds by default.
+// The followers take over at most this long after the leader crashed
+func WithLeaseDuration(d time.Duration) ElectorOption {
+	return func(o *electorOptions) {
+		o.leaseDuration = d
+	}
+}
+
+// WithRenewDeadline set how long the leader keeps trying to renew its lease before stepping down,
+// 10 seconds by default. It must be less than the lease so the leader stops before its lease expires
+func WithRenewDeadline(d time.Duration) ElectorOption {
+	return func(o *electorOptions) {
+		o.renewDeadline = d
+	}
+}
+
+// WithRetryPeriod set the interval of the campaign attempts and of the lease renewals, 2 seconds by default
+func WithRetryPeriod(d time.Duration) ElectorOption {
+	return func(o *electorOptions) {
+		o.retryPeriod = d
+	}
+}
+
+// WithOnStartedLeading set the callback run in a new goroutine when the leadership is acquired,
+// ctx is cancelled when the leadership is lost
+func WithOnStartedLeading(fn func(ctx context.Context)) ElectorOption {
+	return func(o *electorOptions) {
+		o.onStartedLeading = fn
+	}
+}
+
+// WithOnStoppedLeading set the callback called when the leadership is lost or resigned
+func WithOnStoppedLeading(fn func()) ElectorOption {
+	return func(o *electorOptions) {
+		o.onStoppedLeading = fn
+	}
+}
@@ -0,0 +1,216 @@
+package redigo
+
+import (
+	"errors"
+	"github.com/gomodule/redigo/redis"
+	"time"
+)
+
+// commandQueue sends commands on one connection with conn.Send and keeps the result handles
+// in order, so the replies can be dispatched when they are received
+type commandQueue struct {
+	redigo  *Redigo
+	conn    redis.Conn
+	pending []replySetter
+
+	// multi 为true时在第一条命令之前发送MULTI(事务模式)
+	multi   bool
+	inMulti bool
+}
+
+// queue sends the command and appends the handle to the pending list
+func (q *commandQueue) queue(res replySetter, cmd string, args ...any) {
+	if q.multi && !q.inMulti {
+		if err := q.conn.Send("MULTI"); err != nil {
+			res.setReply(nil, err)
+			return
+		}
+		q.inMulti = true
+	}
+	if err := q.conn.Send(cmd, args...); err != nil {
+		res.setReply(nil, err)
+		return
+	}
+	q.pending = append(q.pending, res)
+}
+
+// fail sets err to all pending handles
+func (q *commandQueue) fail(err error) {
+	for _, res := range q.pending {
+		res.setReply(nil, err)
+	}
+	q.pending = nil
+}
+
+// Len returns the number of commands queued since the last Exec
+func (q *commandQueue) Len() int {
+	return len(q.pending)
+}
+
+// Do queues an arbitrary command
+func (q *commandQueue) Do(cmd string, args ...any) *Result {
+	res := &Result{}
+	q.queue(res, cmd, args...)
+	return res
+}
+
+// Get queues a GET command replying a string
+func (q *commandQueue) Get(key string) *StringResult {
+	res := &StringResult{redigo: q.redigo}
+	q.queue(res, "GET", key)
+	return res
+}
+
+// GetInto queues a GET command whose reply is decoded into v like Redigo.Get
+func (q *commandQueue) GetInto(key string, v any) *ScanInto {
+	res := &ScanInto{redigo: q.redigo, v: v}
+	q.queue(res, "GET", key)
+	return res
+}
+
+// Set queues a SET command, the options are the same as Redigo.Set
+func (q *commandQueue) Set(key string, v any, opts ...SetOption) *StatusResult {
+	options := parseSetOptions(opts...)
+	res := &StatusResult{
+		check: func(reply any) error {
+			return q.redigo.checkSetReply(reply, options)
+		},
+	}
+	args, err := q.redigo.setArgs(key, v, options)
+	if err != nil {
+		res.setReply(nil, err)
+		return res
+	}
+	q.queue(res, "SET", args...)
+	return res
+}
+
+// Del queues a DEL command replying the number of keys removed
+func (q *commandQueue) Del(keys ...string) *IntResult {
+	res := &IntResult{}
+	q.queue(res, "DEL", redis.Args{}.AddFlat(keys)...)
+	return res
+}
+
+// Incr queues an INCR command
+func (q *commandQueue) Incr(key string) *IntResult {
+	res := &IntResult{}
+	q.queue(res, "INCR", key)
+	return res
+}
+
+// IncrBy queues an INCRBY command
+func (q *commandQueue) IncrBy(key string, delta int64) *IntResult {
+	res := &IntResult{}
+	q.queue(res, "INCRBY", key, delta)
+	return res
+}
+
+// IncrByFloat queues an INCRBYFLOAT command
+func (q *commandQueue) IncrByFloat(key string, delta float64) *FloatResult {
+	res := &FloatResult{}
+	q.queue(res, "INCRBYFLOAT", key, delta)
+	return res
+}
+
+// Decr queues a DECR command
+func (q *commandQueue) Decr(key string) *IntResult {
+	res := &IntResult{}
+	q.queue(res, "DECR", key)
+	return res
+}
+
+// DecrBy queues a DECRBY command
+func (q *commandQueue) DecrBy(key string, delta int64) *IntResult {
+	res := &IntResult{}
+	q.queue(res, "DECRBY", key, delta)
+	return res
+}
+
+// Expire queues a PEXPIRE command replying whether the timeout was set
+func (q *commandQueue) Expire(key string, expiration time.Duration) *BoolResult {
+	res := &BoolResult{}
+	q.queue(res, "PEXPIRE", key, expiration.Milliseconds())
+	return res
+}
+
+// TTL queues a TTL command replying the remaining seconds
+func (q *commandQueue) TTL(key string) *IntResult {
+	res := &IntResult{}
+	q.queue(res, "TTL", key)
+	return res
+}
+
+// ListPush queues a LPUSH or RPUSH (WithRright) command replying the length of the list
+func (q *commandQueue) ListPush(key string, v any, opts ...ListOption) *IntResult {
+	options := parseListOptions(opts...)
+	res := &IntResult{}
+	cmd, args, err := q.redigo.listPushArgs(key, v, options)
+	if err != nil {
+		res.setReply(nil, err)
+		return res
+	}
+	q.queue(res, cmd, args...)
+	return res
+}
+
+// ListLen queues a LLEN command
+func (q *commandQueue) ListLen(key string) *IntResult {
+	res := &IntResult{}
+	q.queue(res, "LLEN", key)
+	return res
+}
+
+// Pipeline queues commands on one pooled connection and sends them in a single round trip on Exec.
+// Each queued command returns a typed handle which is filled when Exec receives its reply
+type Pipeline struct {
+	commandQueue
+}
+
+// Pipeline returns a new pipeline holding a connection from the pool until Close is called
+func (r *Redigo) Pipeline() (*Pipeline, error) {
+	conn, err := r.getConn()
+	if err != nil {
+		return nil, err
+	}
+	return &Pipeline{
+		commandQueue: commandQueue{
+			redigo: r,
+			conn:   conn,
+		},
+	}, nil
+}
+
+// Exec flushes the queued commands and receives all the replies into their handles.
+// A command replying an error does not abort the batch, the error is reported by its handle.
+// Exec only returns an error when the connection failed, in that case the handles of the
+// remaining commands report the same error. The pipeline can be reused after Exec
+func (p *Pipeline) Exec() error {
+	pending := p.pending
+	p.pending = nil
+	if len(pending) == 0 {
+		return nil
+	}
+	if err := p.conn.Flush(); err != nil {
+		p.pending = pending
+		p.fail(err)
+		return err
+	}
+	for i, res := range pending {
+		reply, err := p.conn.Receive()
+		var redisErr redis.Error
+		if err != nil && !errors.As(err, &redisErr) {
+			p.pending = pending[i:]
+			p.fail(err)
+			return err
+		}
+		res.setReply(reply, err)
+	}
+	return nil
+}
+
+// Close discards the commands not executed yet and returns the connection to the pool
+func (p *Pipeline) Close() error {
+	p.fail(ErrPipelineClosed)
+	return p.conn.Close()
+}
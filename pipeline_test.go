@@ -0,0 +1,96 @@
+package redigo
+
+import (
+	"compress/gzip"
+	"strings"
+	"testing"
+)
+
+const (
+	redigoPipelineKey     = "redigoPipelineKey"
+	redigoPipelineUserKey = "redigoPipelineUserKey"
+)
+
+func TestRedigo_Pipeline(t *testing.T) {
+	redigo := NewRedigo(opts...)
+	pipe, err := redigo.Pipeline()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pipe.Close()
+
+	var user User
+	pipe.Del(redigoPipelineKey, redigoPipelineUserKey)
+	set := pipe.Set(redigoPipelineUserKey, &User{ID: 10086, Name: "dianxin"}, WithEX(expireSeconds))
+	get := pipe.GetInto(redigoPipelineUserKey, &user)
+	incr := pipe.Incr(redigoPipelineKey)
+	bad := pipe.ListPush(redigoPipelineKey, "wrong type")
+	incrBy := pipe.IncrBy(redigoPipelineKey, 10)
+	missing := pipe.Get(redigoNotFoundKey)
+	if pipe.Len() != 7 {
+		t.Fatalf("expected 7 queued commands, got %d", pipe.Len())
+	}
+	if err = pipe.Exec(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = set.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if err = get.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if user.Name != "dianxin" {
+		t.Fatalf("unexpected user %+v", user)
+	}
+	n, err := incr.Result()
+	if err != nil || n != 1 {
+		t.Fatalf("INCR returned %v %v", n, err)
+	}
+	if bad.Err() == nil {
+		t.Fatal("expected WRONGTYPE error for LPUSH on a string key")
+	}
+	n, err = incrBy.Result()
+	if err != nil || n != 11 {
+		t.Fatalf("INCRBY returned %v %v", n, err)
+	}
+	if _, err = missing.Result(); err == nil {
+		t.Fatal("expected redis.ErrNil for a missing key")
+	}
+	t.Logf("pipeline executed, user %+v counter %v", user, n)
+}
+
+func TestRedigo_PipelineCompression(t *testing.T) {
+	large := strings.Repeat("redigo pipeline ", 100)
+	redigo := NewRedigo(append(opts, WithCompression(GzipCompressor(gzip.BestSpeed), 1024))...)
+	if err := redigo.Set(redigoPipelineKey, large, WithEX(expireSeconds)); err != nil {
+		t.Fatal(err)
+	}
+
+	// 管道和事务中的Get同样返回解压后的值
+	pipe, err := redigo.Pipeline()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pipe.Close()
+	get := pipe.Get(redigoPipelineKey)
+	if err = pipe.Exec(); err != nil {
+		t.Fatal(err)
+	}
+	if s, err := get.Result(); err != nil || s != large {
+		t.Fatalf("unexpected pipeline value of %d bytes (%v)", len(s), err)
+	}
+
+	tx, err := redigo.Tx()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Close()
+	get = tx.Get(redigoPipelineKey)
+	if err = tx.Exec(); err != nil {
+		t.Fatal(err)
+	}
+	if s, err := get.Result(); err != nil || s != large {
+		t.Fatalf("unexpected transaction value of %d bytes (%v)", len(s), err)
+	}
+}
@@ -1,7 +1,7 @@
 package redigo
 
 import (
-	"encoding/json"
+	"context"
 	"github.com/gomodule/redigo/redis"
 	"time"
 )
@@ -11,8 +11,11 @@ const (
 )
 
 type Redigo struct {
-	pool    *redis.Pool
-	options *redigoOptions
+	pool     *redis.Pool
+	options  *redigoOptions
+	scripts  *scriptRegistry
+	metrics  *lockMetrics
+	notifier *unlockNotifier
 }
 
 func NewRedigo(opts ...Option) *Redigo {
@@ -23,35 +26,29 @@ func NewRedigo(opts ...Option) *Redigo {
 	if err := checkParams(options); err != nil {
 		panic(err.Error())
 	}
-	pool := &redis.Pool{
+	r := &Redigo{
+		options: options,
+		scripts: newScriptRegistry(options.scripts...),
+		metrics: &lockMetrics{},
+	}
+	r.notifier = newUnlockNotifier(r.dial)
+	r.pool = &redis.Pool{
 		MaxActive:       options.maxActive,
 		MaxIdle:         options.maxIdle,
 		IdleTimeout:     options.idleTimeout,
 		Wait:            options.Wait,
 		MaxConnLifetime: options.MaxConnLifetime,
-		Dial: func() (redis.Conn, error) {
-			dialOptions := []redis.DialOption{
-				redis.DialDatabase(options.db),
-			}
-			if options.password != "" {
-				dialOptions = append(dialOptions, redis.DialPassword(options.password))
-			}
-			if options.connTimeout != nil {
-				dialOptions = append(dialOptions, redis.DialConnectTimeout(*options.connTimeout))
-			}
-			if options.clientName != "" {
-				dialOptions = append(dialOptions, redis.DialClientName(options.clientName))
+		DialContext: func(ctx context.Context) (redis.Conn, error) {
+			conn, err := r.dial(ctx)
+			if err != nil {
+				return nil, err
 			}
-			if options.useTLS {
-				dialOptions = append(dialOptions, redis.DialUseTLS(options.useTLS))
+			// 新连接预加载已注册的脚本, 尽力而为: 没有SCRIPT权限时EVALSHA仍会回退到EVAL
+			if err = r.scripts.load(ctx, conn); err != nil && conn.Err() != nil {
+				_ = conn.Close()
+				return nil, err
 			}
-			if options.skipVerify {
-				dialOptions = append(dialOptions, redis.DialTLSSkipVerify(options.skipVerify))
-			}
-			if options.tlsConfig != nil {
-				dialOptions = append(dialOptions, redis.DialTLSConfig(options.tlsConfig))
-			}
-			return redis.Dial("tcp", options.address, dialOptions...)
+			return conn, nil
 		},
 		TestOnBorrow: func(c redis.Conn, t time.Time) error {
 			if time.Since(t) < time.Minute {
@@ -61,10 +58,36 @@ func NewRedigo(opts ...Option) *Redigo {
 			return err
 		},
 	}
-	return &Redigo{
-		pool:    pool,
-		options: options,
+	if err := r.loadFunctionLibraries(); err != nil {
+		panic(err.Error())
 	}
+	return r
+}
+
+// dial 使用客户端选项建立新连接, ctx结束时中断连接和认证
+func (r *Redigo) dial(ctx context.Context) (redis.Conn, error) {
+	dialOptions := []redis.DialOption{
+		redis.DialDatabase(r.options.db),
+	}
+	if r.options.password != "" {
+		dialOptions = append(dialOptions, redis.DialPassword(r.options.password))
+	}
+	if r.options.connTimeout != nil {
+		dialOptions = append(dialOptions, redis.DialConnectTimeout(*r.options.connTimeout))
+	}
+	if r.options.clientName != "" {
+		dialOptions = append(dialOptions, redis.DialClientName(r.options.clientName))
+	}
+	if r.options.useTLS {
+		dialOptions = append(dialOptions, redis.DialUseTLS(r.options.useTLS))
+	}
+	if r.options.skipVerify {
+		dialOptions = append(dialOptions, redis.DialTLSSkipVerify(r.options.skipVerify))
+	}
+	if r.options.tlsConfig != nil {
+		dialOptions = append(dialOptions, redis.DialTLSConfig(r.options.tlsConfig))
+	}
+	return redis.DialContext(ctx, "tcp", r.options.address, dialOptions...)
 }
 
 func newDefaultOptions() *redigoOptions {
@@ -76,6 +99,7 @@ func newDefaultOptions() *redigoOptions {
 		maxActive:   150,
 		idleTimeout: defaultIdleTimeout,
 		Wait:        true,
+		codec:       JSONCodec(),
 	}
 }
 
@@ -107,290 +131,20 @@ func (r *Redigo) Get(key string, v any) error {
 
 func (r *Redigo) Set(key string, v any, opts ...SetOption) error {
 	options := parseSetOptions(opts...)
-
-	conn, err := r.getConn()
-	if err != nil {
-		return err
-	}
-	defer conn.Close()
-
-	var data any
-	if isBasicType(v) {
-		data = v
-	} else {
-		jsonData, err := json.Marshal(v)
-		if err != nil {
-			return err
-		}
-		data = string(jsonData)
-	}
-	var args = []any{
-		key,
-		data,
-	}
-	if options.ex != 0 {
-		args = append(args, "EX", options.ex)
-	} else if options.px != 0 {
-		args = append(args, "PX", options.px)
-	}
-	if options.nx {
-		args = append(args, "NX")
-	} else if options.xx {
-		args = append(args, "XX")
-	}
-	reply, err := conn.Do("SET", args...)
-	if err != nil {
-		return err
-	}
-
-	// 处理条件性设置选项的返回值
-	if options.nx || options.xx {
-		if reply == nil {
-			if options.nx {
-				return ErrKeyExists
-			} else {
-				return ErrKeyNotExists
-			}
-		}
-	}
-
-	// 检查是否为标准OK响应
-	if err = checkOK(reply, err); err != nil {
-		return err
-	}
-	return nil
-}
-
-func (r *Redigo) Del(key string) (int64, error) {
-	conn, err := r.getConn()
-	if err != nil {
-		return 0, err
-	}
-	defer conn.Close()
-
-	reply, err := conn.Do("DEL", key)
-	if err != nil {
-		return 0, err
-	}
-
-	var ret int64
-	if err = r.scanReply(reply, &ret); err != nil {
-		return 0, err
-	}
-	return ret, nil
-}
-
-func (r *Redigo) Exists(key string) (bool, error) {
-	conn, err := r.getConn()
-	if err != nil {
-		return false, err
-	}
-	defer conn.Close()
-
-	reply, err := conn.Do("EXISTS", key)
-	if err != nil {
-		return false, err
-	}
-
-	// 检查是否为标准OK响应
-	if err = checkOK(reply, err); err != nil {
-		return false, err
-	}
-	return true, nil
-}
-
-func (r *Redigo) TTL(key string) (int64, error) {
-	conn, err := r.getConn()
-	if err != nil {
-		return 0, err
-	}
-	defer conn.Close()
-
-	reply, err := conn.Do("TTL", key)
-	if err != nil {
-		return 0, err
-	}
-
-	var ttl int64
-	if err = r.scanReply(reply, &ttl); err != nil {
-		return 0, err
-	}
-	return ttl, nil
-}
-
-func (r *Redigo) Expire(key string, expiration time.Duration) error {
-	conn, err := r.getConn()
-	if err != nil {
-		return err
-	}
-	defer conn.Close()
-
-	reply, err := conn.Do("EXPIRE", key, expiration.Seconds())
-	if err != nil {
-		return err
-	}
-
-	// 检查是否为标准OK响应
-	if err = checkOK(reply, err); err != nil {
-		return err
-	}
-	return nil
-}
-
-// Incr increment the value of a key by 1 if v is nil, otherwise v must be an integer or float number
-func (r *Redigo) Incr(key string, v any) (reply any, err error) {
-	conn, err := r.getConn()
-	if err != nil {
-		return 0, err
-	}
-	defer conn.Close()
-
-	var incr = "INCR"
-	var args []any
-	if v != nil {
-		switch v.(type) {
-		case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
-			incr = "INCRBY"
-		case float32, float64:
-			incr = "INCRBYFLOAT"
-		default:
-			panic("value must be an integer or float number")
-		}
-	}
-	args = append(args, key)
-	if v != nil {
-		args = append(args, v)
-	}
-	return conn.Do(incr, args...)
-}
-
-func (r *Redigo) Decr(key string, v ...int64) (reply any, err error) {
-	conn, err := r.getConn()
-	if err != nil {
-		return 0, err
-	}
-	defer conn.Close()
-	var delta = int64(1)
-	if len(v) != 0 {
-		delta = v[0]
-	}
-	return conn.Do("DECRBY", key, delta)
-}
-
-func (r *Redigo) ListPush(key string, v any, opts ...ListOption) (ret int64, err error) {
-	options := parseListOptions(opts...)
-	conn, err := r.getConn()
-	if err != nil {
-		return 0, err
-	}
-	defer conn.Close()
-
-	var args []any
-	var values []any
-	if options.unwind {
-		values = unwind(v)
-	} else {
-		values = []any{v}
-	}
-	args = append(args, key)
-	args = append(args, values...)
-
-	var reply any
-	if options.right {
-		if options.block {
-			reply, err = conn.Do("BRPUSH", args...)
-		} else {
-			reply, err = conn.Do("RPUSH", args...)
-		}
-	} else {
-		if options.block {
-			reply, err = conn.Do("BLPUSH", args...)
-		} else {
-			reply, err = conn.Do("LPUSH", args...)
-		}
-	}
-	if err != nil {
-		return 0, err
-	}
-
-	if err = r.scanReply(reply, &ret); err != nil {
-		return 0, err
-	}
-	return ret, nil
-}
-
-// 阻塞模式(BRPOP/BLPOP) n表示超时时间(s), 否则n表示取值个数
-func (r *Redigo) ListPop(key string, n int, v any, opts ...ListOption) (err error) {
-	options := parseListOptions(opts...)
-	conn, err := r.getConn()
-	if err != nil {
-		return err
-	}
-	defer conn.Close()
-
-	var reply any
-	if options.right {
-		if options.block {
-			reply, err = conn.Do("BRPOP", key, n)
-		} else {
-			reply, err = conn.Do("RPOP", key, n)
-		}
-	} else {
-		if options.block {
-			reply, err = conn.Do("BLPOP", key, n)
-		} else {
-			reply, err = conn.Do("LPOP", key, n)
-		}
-	}
-	if err != nil {
-		return err
-	}
-
-	values, err := redis.Values(reply, err)
+	args, err := r.setArgs(key, v, options)
 	if err != nil {
 		return err
 	}
 
-	// 将结果扫描到切片
-	if err = redis.ScanSlice(values, v); err != nil {
-		return err
-	}
-	return nil
-}
-
-func (r *Redigo) ListLen(key string) (n int64, err error) {
-	conn, err := r.getConn()
-	if err != nil {
-		return 0, err
-	}
-	defer conn.Close()
-
-	reply, err := conn.Do("LLEN", key)
-	if err != nil {
-		return 0, err
-	}
-	if err = r.scanReply(reply, &n); err != nil {
-		return 0, err
-	}
-	return n, nil
-}
-
-func (r *Redigo) ListRange(key string, start, stop int64, v any) (err error) {
 	conn, err := r.getConn()
 	if err != nil {
 		return err
 	}
 	defer conn.Close()
 
-	// 执行 LRANGE 命令
-	values, err := redis.Values(conn.Do("LRANGE", key, start, stop))
+	reply, err := conn.Do("SET", args...)
 	if err != nil {
 		return err
 	}
-
-	// 将结果扫描到切片
-	if err = redis.ScanSlice(values, v); err != nil {
-		return err
-	}
-	return nil
+	return r.checkSetReply(reply, options)
 }
@@ -0,0 +1,244 @@
+package redigo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	// redlockDriftFactor is the clock drift between the instances relative to the expiry
+	redlockDriftFactor = 0.01
+	// redlockDriftMin is added to the clock drift to account for the precision of the server expiry
+	redlockDriftMin = 2 * time.Millisecond
+	// redlockTimeoutFactor is the timeout of the request to an instance relative to the expiry,
+	// so an unreachable instance does not use up the validity of the lock
+	redlockTimeoutFactor = 0.02
+	// redlockTimeoutMin is the minimum timeout of the request to an instance
+	redlockTimeoutMin = 50 * time.Millisecond
+)
+
+// Redlock is a distributed lock acquired on a majority of independent Redis instances (the Redlock
+// algorithm), it stays safe when a minority of the instances fail or lose their data.
+// The expiry, retry strategy and max attempts are set with the same LockOption as LockContext
+type Redlock struct {
+	key     string
+	clients []*Redigo
+	options *lockOptions
+
+	locker sync.Mutex
+	value  string
+	expiry time.Duration // lease of the held lock, changed by Extend
+	until  time.Time
+}
+
+// NewRedlock returns a Redlock of key on the independent instances clients
+func NewRedlock(key string, clients []*Redigo, opts ...LockOption) *Redlock {
+	return &Redlock{
+		key:     key,
+		clients: clients,
+		options: parseLockOptions(opts...),
+	}
+}
+
+// Key returns the key of the lock
+func (rl *Redlock) Key() string {
+	return rl.key
+}
+
+// Quorum returns the number of instances on which the lock must be acquired
+func (rl *Redlock) Quorum() int {
+	return len(rl.clients)/2 + 1
+}
+
+// Until returns the end of the validity of the lock, the zero time if it is not held
+func (rl *Redlock) Until() time.Time {
+	rl.locker.Lock()
+	defer rl.locker.Unlock()
+	return rl.until
+}
+
+// Lock acquires the lock on a quorum of instances. An attempt succeeds only if the remaining validity,
+// the expiry minus the time spent acquiring and the clock drift, is still positive, otherwise the
+// partially acquired lock is released on all instances and the attempt is retried like LockContext
+func (rl *Redlock) Lock(ctx context.Context) error {
+	rl.locker.Lock()
+	defer rl.locker.Unlock()
+
+	for attempt := 1; ; attempt++ {
+		// 每次尝试使用新的值, 上次尝试的锁在后台释放时不会误删
+		value, err := newLockValue(rl.options)
+		if err != nil {
+			return err
+		}
+		until, err := rl.acquire(ctx, value)
+		if err == nil {
+			rl.value = value
+			rl.expiry = rl.options.expiry
+			rl.until = until
+			return nil
+		}
+
+		if options := rl.options; options.maxAttempts > 0 && attempt >= options.maxAttempts {
+			return err
+		}
+		backoff, ok := rl.options.retry.NextBackoff(attempt)
+		if !ok {
+			return err
+		}
+
+		// Wait a bit before retrying
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("%w: %w", ErrLockAcquisitionFailed, ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+// acquire 在所有实例上并发加锁, 未达到多数或有效期耗尽时释放已获取的锁
+func (rl *Redlock) acquire(ctx context.Context, value string) (time.Time, error) {
+	expiry := rl.options.expiry
+	start := time.Now()
+
+	n, done, err := rl.forEach(ctx, expiry, true, func(ctx context.Context, r *Redigo) (bool, error) {
+		acquired, _, err := r.tryLock(ctx, rl.key, value, expiry, false)
+		return acquired, err
+	})
+	if until, ok := rl.valid(start, expiry, n); ok {
+		return until, nil
+	}
+
+	// 释放前仍在进行的请求可能在释放之后加锁成功, 结束后再释放一次
+	var finished bool
+	select {
+	case <-done:
+		finished = true
+	default:
+	}
+	_, _ = rl.release(value, expiry)
+	if !finished {
+		go func() {
+			<-done
+			_, _ = rl.release(value, expiry)
+		}()
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %w", ErrLockAcquisitionFailed, err)
+	}
+	return time.Time{}, ErrLockAcquisitionFailed
+}
+
+// valid 判断n个实例成功时锁是否有效, 并返回有效期截止时间
+func (rl *Redlock) valid(start time.Time, expiry time.Duration, n int) (time.Time, bool) {
+	drift := time.Duration(float64(expiry)*redlockDriftFactor) + redlockDriftMin
+	validity := expiry - time.Since(start) - drift
+	if n < rl.Quorum() || validity <= 0 {
+		return time.Time{}, false
+	}
+	return start.Add(expiry - drift), true
+}
+
+// Unlock releases the lock on all instances, it returns ErrLockNotHeld if it was
+// released on less than a quorum of instances
+func (rl *Redlock) Unlock() error {
+	rl.locker.Lock()
+	defer rl.locker.Unlock()
+	if rl.value == "" {
+		return ErrLockNotHeld
+	}
+
+	n, err := rl.release(rl.value, rl.expiry)
+	rl.value = ""
+	rl.expiry = 0
+	rl.until = time.Time{}
+	if n < rl.Quorum() {
+		return errors.Join(ErrLockNotHeld, err)
+	}
+	return nil
+}
+
+func (rl *Redlock) release(value string, expiry time.Duration) (int, error) {
+	n, _, err := rl.forEach(context.Background(), expiry, false, func(ctx context.Context, r *Redigo) (bool, error) {
+		result, err := r.evalScriptContext(ctx, unlockScript, rl.key, value, lockChannel(rl.key))
+		return result == int64(1), err
+	})
+	return n, err
+}
+
+// Extend resets the expiry of the lock to d on all instances, it returns ErrLockNotHeld
+// if the lock could not be extended on a quorum of instances within the new validity
+func (rl *Redlock) Extend(d time.Duration) error {
+	rl.locker.Lock()
+	defer rl.locker.Unlock()
+	if rl.value == "" {
+		return ErrLockNotHeld
+	}
+
+	start := time.Now()
+	n, _, err := rl.forEach(context.Background(), d, true, func(ctx context.Context, r *Redigo) (bool, error) {
+		result, err := r.evalScriptContext(ctx, extendScript, rl.key, rl.value, d.Milliseconds())
+		return result == int64(1), err
+	})
+	until, ok := rl.valid(start, d, n)
+	if !ok {
+		return errors.Join(ErrLockNotHeld, err)
+	}
+	rl.expiry = d
+	rl.until = until
+	return nil
+}
+
+// timeout 返回单个实例请求的超时时间, 为有效期的一小部分
+func (rl *Redlock) timeout(expiry time.Duration) time.Duration {
+	return max(time.Duration(float64(expiry)*redlockTimeoutFactor), redlockTimeoutMin)
+}
+
+// forEach 在所有实例上并发执行fn, 每个实例的请求超时为expiry的一小部分, 返回成功的实例数量和错误.
+// decide为true时, 成功的实例达到多数或已不可能达到多数即返回, 其余实例在后台继续执行, 全部结束后关闭done
+func (rl *Redlock) forEach(ctx context.Context, expiry time.Duration, decide bool, fn func(ctx context.Context, r *Redigo) (bool, error)) (int, <-chan struct{}, error) {
+	type result struct {
+		ok  bool
+		err error
+	}
+	var timeout = rl.timeout(expiry)
+	var results = make(chan result, len(rl.clients))
+	var wg sync.WaitGroup
+	for _, client := range rl.clients {
+		wg.Add(1)
+		go func(r *Redigo) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			ok, err := fn(ctx, r)
+			results <- result{ok: ok, err: err}
+		}(client)
+	}
+	var done = make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	var n, failed int
+	var errs []error
+	for range rl.clients {
+		res := <-results
+		if res.ok {
+			n++
+		} else {
+			failed++
+		}
+		if res.err != nil {
+			errs = append(errs, res.err)
+		}
+		if decide && (n >= rl.Quorum() || failed > len(rl.clients)-rl.Quorum()) {
+			break
+		}
+	}
+	return n, done, errors.Join(errs...)
+}
@@ -0,0 +1,189 @@
+package redigo
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	testRedlockKey = "test_redlock_key"
+)
+
+// 三个相互独立的redis实例
+var redlockAddresses = []string{
+	"127.0.0.1:6379",
+	"127.0.0.1:6380",
+	"127.0.0.1:6381",
+}
+
+func newRedlockClients(addresses ...string) []*Redigo {
+	var clients []*Redigo
+	for _, address := range addresses {
+		clients = append(clients, NewRedigo(WithAddress(address), WithPassword(redisPassword)))
+	}
+	return clients
+}
+
+func TestRedlock(t *testing.T) {
+	clients := newRedlockClients(redlockAddresses...)
+	rl1 := NewRedlock(testRedlockKey, clients, WithLockExpiry(10*time.Second))
+	rl2 := NewRedlock(testRedlockKey, clients, WithLockExpiry(10*time.Second), WithRetryStrategy(NoRetry()))
+
+	if err := rl1.Lock(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if until := rl1.Until(); time.Until(until) <= 9*time.Second {
+		t.Fatalf("unexpected validity %v", until)
+	}
+	if err := rl2.Lock(context.Background()); !errors.Is(err, ErrLockAcquisitionFailed) {
+		t.Fatalf("expected ErrLockAcquisitionFailed, got %v", err)
+	}
+
+	if err := rl1.Extend(time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if until := rl1.Until(); time.Until(until) <= 50*time.Second {
+		t.Fatalf("unexpected validity after extend %v", until)
+	}
+	if err := rl1.Unlock(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 续期不改变之后加锁的租期
+	if err := rl1.Lock(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if until := rl1.Until(); time.Until(until) > 10*time.Second {
+		t.Fatalf("expected the configured expiry after Extend, got validity %v", time.Until(until))
+	}
+	if err := rl1.Unlock(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := rl2.Lock(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := rl2.Unlock(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRedlock_Quorum(t *testing.T) {
+	// 一个实例不可用时仍能在多数实例上获取锁
+	clients := newRedlockClients(redlockAddresses[0], redlockAddresses[1], "127.0.0.1:6390")
+	rl := NewRedlock(testRedlockKey, clients, WithLockExpiry(10*time.Second), WithMaxAttempts(3))
+	if rl.Quorum() != 2 {
+		t.Fatalf("unexpected quorum %d", rl.Quorum())
+	}
+	if err := rl.Lock(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := rl.Unlock(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 多数实例不可用时获取失败
+	clients = newRedlockClients(redlockAddresses[0], "127.0.0.1:6390", "127.0.0.1:6391")
+	rl = NewRedlock(testRedlockKey, clients, WithLockExpiry(10*time.Second), WithRetryStrategy(NoRetry()))
+	if err := rl.Lock(context.Background()); !errors.Is(err, ErrLockAcquisitionFailed) {
+		t.Fatalf("expected ErrLockAcquisitionFailed, got %v", err)
+	}
+	// 仍在进行的加锁请求结束后在后台释放
+	redigo := NewRedigo(opts...)
+	for deadline := time.Now().Add(time.Second); ; time.Sleep(10 * time.Millisecond) {
+		if held, _ := redigo.Exists(testRedlockKey); held == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("partially acquired lock not released")
+		}
+	}
+}
+
+// blackhole 接受连接但从不响应, 模拟挂起的实例
+func blackhole(t *testing.T) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var locker sync.Mutex
+	var conns []net.Conn
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			locker.Lock()
+			conns = append(conns, conn)
+			locker.Unlock()
+		}
+	}()
+	t.Cleanup(func() {
+		_ = l.Close()
+		locker.Lock()
+		defer locker.Unlock()
+		for _, conn := range conns {
+			_ = conn.Close()
+		}
+	})
+	return l.Addr().String()
+}
+
+func TestRedlock_Blackhole(t *testing.T) {
+	// 挂起的实例只占用单个实例的超时, 不会耗尽锁的有效期
+	clients := newRedlockClients(redlockAddresses[0], redlockAddresses[1], blackhole(t))
+	rl := NewRedlock(testRedlockKey, clients, WithLockExpiry(10*time.Second), WithRetryStrategy(NoRetry()))
+	start := time.Now()
+	if err := rl.Lock(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("lock took %v", elapsed)
+	}
+	if until := rl.Until(); time.Until(until) <= 9*time.Second {
+		t.Fatalf("unexpected validity %v", until)
+	}
+	if err := rl.Unlock(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 没有密码时连接不发送命令, 预加载脚本的等待同样受超时限制
+	clients = append(newRedlockClients(redlockAddresses[0], redlockAddresses[1]), NewRedigo(WithAddress(blackhole(t))))
+	rl = NewRedlock(testRedlockKey, clients, WithLockExpiry(10*time.Second), WithRetryStrategy(NoRetry()))
+	if err := rl.Lock(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	start = time.Now()
+	if err := rl.Unlock(); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("unlock took %v", elapsed)
+	}
+
+	// 多数实例挂起时很快失败
+	clients = newRedlockClients(redlockAddresses[0], blackhole(t), blackhole(t))
+	rl = NewRedlock(testRedlockKey, clients, WithLockExpiry(10*time.Second), WithRetryStrategy(NoRetry()))
+	start = time.Now()
+	if err := rl.Lock(context.Background()); !errors.Is(err, ErrLockAcquisitionFailed) {
+		t.Fatalf("expected ErrLockAcquisitionFailed, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("failed after %v", elapsed)
+	}
+	// 仍在进行的加锁请求结束后在后台释放
+	redigo := NewRedigo(opts...)
+	for deadline := time.Now().Add(time.Second); ; time.Sleep(10 * time.Millisecond) {
+		if held, _ := redigo.Exists(testRedlockKey); held == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("partially acquired lock not released")
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package redigo
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// reentrantAcquireScript increments the hold count of the owner ARGV[1] if the lock is free or
+// already held by it, then resets the expiry (in milliseconds). It returns the new hold count, 0 if
+// the lock is held by someone else
+var reentrantAcquireScript = NewScript(1, `
+	if redis.call("EXISTS", KEYS[1]) == 0 or redis.call("HEXISTS", KEYS[1], ARGV[1]) == 1 then
+		local count = redis.call("HINCRBY", KEYS[1], ARGV[1], 1)
+		redis.call("PEXPIRE", KEYS[1], ARGV[2])
+		return count
+	end
+	return 0
+`)
+
+// reentrantReleaseScript decrements the hold count of the owner ARGV[1] and deletes the lock when it
+// reaches zero, publishing the key to the channel ARGV[3]. It returns the remaining hold count,
+// -1 if the lock is not held by the owner
+var reentrantReleaseScript = NewScript(1, `
+	if redis.call("HEXISTS", KEYS[1], ARGV[1]) == 0 then
+		return -1
+	end
+	local count = redis.call("HINCRBY", KEYS[1], ARGV[1], -1)
+	if count > 0 then
+		redis.call("PEXPIRE", KEYS[1], ARGV[2])
+		return count
+	end
+	redis.call("DEL", KEYS[1])
+	redis.call("PUBLISH", ARGV[3], KEYS[1])
+	return 0
+`)
+
+// reentrantExtendScript resets the expiry (in milliseconds) of the lock if it is held by the owner ARGV[1]
+var reentrantExtendScript = NewScript(1, `
+	if redis.call("HEXISTS", KEYS[1], ARGV[1]) == 1 then
+		return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+	end
+	return 0
+`)
+
+// reentrantCountScript returns the hold count of the owner ARGV[1], 0 if it does not hold the lock
+var reentrantCountScript = NewScript(1, `
+	return tonumber(redis.call("HGET", KEYS[1], ARGV[1]) or "0")
+`)
+
+type lockOwnerKey struct{}
+
+// ContextWithLockOwner returns a copy of ctx bound to the lock owner identity owner,
+// reentrant locks acquired with the returned context (or a child of it) share the same owner
+func ContextWithLockOwner(ctx context.Context, owner string) context.Context {
+	return context.WithValue(ctx, lockOwnerKey{}, owner)
+}
+
+// LockOwnerFromContext returns the lock owner identity bound to ctx
+func LockOwnerFromContext(ctx context.Context) (string, bool) {
+	owner, ok := ctx.Value(lockOwnerKey{}).(string)
+	return owner, ok && owner != ""
+}
+
+// ReentrantLock is a hold on a reentrant distributed lock. The lock is stored as a hash of
+// owner token to hold count, it is released when every hold of the owner has been unlocked
+type ReentrantLock struct {
+	key    string
+	owner  string
+	ctx    context.Context
+	redigo *Redigo
+
+	locker   sync.Mutex
+	expiry   time.Duration
+	acquired bool
+}
+
+// LockReentrant acquires the reentrant lock key for the owner bound to ctx (see ContextWithLockOwner),
+// retrying like LockContext while it is held by another owner. A new owner is generated if ctx has none,
+// pass Context() of the returned lock to nested calls so they acquire the lock again instead of deadlocking.
+// Each successful call adds a hold which must be released by Unlock of the returned lock
+func (r *Redigo) LockReentrant(ctx context.Context, key string, opts ...LockOption) (*ReentrantLock, error) {
+	options := parseLockOptions(opts...)
+	owner, ok := LockOwnerFromContext(ctx)
+	if !ok {
+		var err error
+		if owner, err = randomValue(); err != nil {
+			return nil, err
+		}
+		ctx = ContextWithLockOwner(ctx, owner)
+	}
+
+	err := r.acquire(ctx, key, options, func(ctx context.Context) (bool, error) {
+		count, err := r.evalScriptContext(ctx, reentrantAcquireScript, key, owner, options.expiry.Milliseconds())
+		if err != nil {
+			return false, err
+		}
+		return count != int64(0), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &ReentrantLock{
+		key:      key,
+		owner:    owner,
+		ctx:      ctx,
+		redigo:   r,
+		expiry:   options.expiry,
+		acquired: true,
+	}, nil
+}
+
+// Key returns the key of the lock
+func (l *ReentrantLock) Key() string {
+	return l.key
+}
+
+// Owner returns the owner identity holding the lock
+func (l *ReentrantLock) Owner() string {
+	return l.owner
+}
+
+// Context returns the context bound to the owner of the lock, to be passed to nested acquisitions
+func (l *ReentrantLock) Context() context.Context {
+	return l.ctx
+}
+
+// Unlock releases this hold of the lock, the lock is deleted when the owner releases its last hold.
+// It returns ErrLockNotHeld if the lock has expired or this hold was already released
+func (l *ReentrantLock) Unlock() error {
+	l.locker.Lock()
+	defer l.locker.Unlock()
+	if !l.acquired {
+		return ErrLockNotHeld
+	}
+
+	result, err := l.redigo.evalScript(reentrantReleaseScript, l.key, l.owner, l.expiry.Milliseconds(), lockChannel(l.key))
+	if err != nil {
+		return err
+	}
+	l.acquired = false
+	if result == int64(-1) {
+		return ErrLockNotHeld
+	}
+	return nil
+}
+
+// Extend resets the expiry of the lock to d from now, subsequent holds and releases use d as well.
+// It returns ErrLockNotHeld if the lock has expired or is held by someone else
+func (l *ReentrantLock) Extend(d time.Duration) error {
+	l.locker.Lock()
+	defer l.locker.Unlock()
+	if !l.acquired {
+		return ErrLockNotHeld
+	}
+
+	result, err := l.redigo.evalScript(reentrantExtendScript, l.key, l.owner, d.Milliseconds())
+	if err != nil {
+		return err
+	}
+	if result == int64(0) {
+		return ErrLockNotHeld
+	}
+	l.expiry = d
+	return nil
+}
+
+// Count returns the number of holds of the owner on the lock, 0 if the owner does not hold it anymore
+func (l *ReentrantLock) Count() (int64, error) {
+	var count int64
+	if err := l.redigo.RunScript(reentrantCountScript, &count, l.key, l.owner); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
@@ -0,0 +1,91 @@
+package redigo
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+const (
+	testReentrantLockKey = "test_reentrant_lock_key"
+)
+
+func TestRedigo_LockReentrant(t *testing.T) {
+	redigo := NewRedigo(opts...)
+	_, _ = redigo.Del(testReentrantLockKey)
+
+	lock, err := redigo.LockReentrant(context.Background(), testReentrantLockKey, WithLockExpiry(5*time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 同一上下文再次加锁成功
+	nested, err := redigo.LockReentrant(lock.Context(), testReentrantLockKey, WithLockExpiry(5*time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nested.Owner() != lock.Owner() {
+		t.Fatalf("expected owner %s, got %s", lock.Owner(), nested.Owner())
+	}
+	if count, err := lock.Count(); err != nil || count != 2 {
+		t.Fatalf("expected 2 holds, got %d (%v)", count, err)
+	}
+
+	// 其他上下文加锁失败
+	_, err = redigo.LockReentrant(context.Background(), testReentrantLockKey, WithMaxAttempts(2))
+	if !errors.Is(err, ErrLockAcquisitionFailed) {
+		t.Fatalf("expected ErrLockAcquisitionFailed, got %v", err)
+	}
+
+	if err = lock.Extend(10 * time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if err = nested.Unlock(); err != nil {
+		t.Fatal(err)
+	}
+	if err = nested.Unlock(); !errors.Is(err, ErrLockNotHeld) {
+		t.Fatalf("expected ErrLockNotHeld, got %v", err)
+	}
+	if n, _ := redigo.Exists(testReentrantLockKey); n != 1 {
+		t.Fatal("lock released before the last hold")
+	}
+	if err = lock.Unlock(); err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := redigo.Exists(testReentrantLockKey); n != 0 {
+		t.Fatal("lock not released after the last hold")
+	}
+}
+
+func TestRedigo_LockReentrantOwner(t *testing.T) {
+	redigo := NewRedigo(opts...)
+	_, _ = redigo.Del(testReentrantLockKey)
+
+	ctx := ContextWithLockOwner(context.Background(), "worker-1")
+	if owner, ok := LockOwnerFromContext(ctx); !ok || owner != "worker-1" {
+		t.Fatalf("unexpected owner %q", owner)
+	}
+	lock, err := redigo.LockReentrant(ctx, testReentrantLockKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lock.Unlock()
+	if lock.Owner() != "worker-1" {
+		t.Fatalf("expected owner worker-1, got %s", lock.Owner())
+	}
+
+	// 释放时通知等待者
+	waitCtx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	go func() {
+		time.Sleep(200 * time.Millisecond)
+		_ = lock.Unlock()
+	}()
+	other, err := redigo.LockReentrant(waitCtx, testReentrantLockKey, WithRetryStrategy(FixedRetry(time.Minute)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = other.Unlock(); err != nil {
+		t.Fatal(err)
+	}
+}
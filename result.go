@@ -0,0 +1,127 @@
+package redigo
+
+import (
+	"github.com/gomodule/redigo/redis"
+)
+
+// replySetter receives the reply of a queued command once it has been executed
+type replySetter interface {
+	setReply(reply any, err error)
+}
+
+// result holds the raw reply of a queued command
+type result struct {
+	reply any
+	err   error
+	done  bool
+}
+
+func (res *result) setReply(reply any, err error) {
+	res.reply = reply
+	res.err = err
+	res.done = true
+}
+
+// raw returns the raw reply, or ErrResultPending if the command has not been executed yet
+func (res *result) raw() (any, error) {
+	if !res.done {
+		return nil, ErrResultPending
+	}
+	return res.reply, res.err
+}
+
+// Err returns the error of the command, nil if the command succeeded
+func (res *result) Err() error {
+	_, err := res.raw()
+	return err
+}
+
+// Result is the handle of a queued command with an untyped reply
+type Result struct {
+	result
+}
+
+// Result returns the raw reply of the command
+func (res *Result) Result() (any, error) {
+	return res.raw()
+}
+
+// StringResult is the handle of a queued command replying a string
+type StringResult struct {
+	result
+	redigo *Redigo
+}
+
+// Result returns the string reply, decompressed and decrypted like Get, redis.ErrNil if the reply is nil
+func (res *StringResult) Result() (string, error) {
+	reply, err := res.raw()
+	if err != nil {
+		return "", err
+	}
+	// 压缩或加密的值需要先解包
+	if data, ok := reply.([]byte); ok && res.redigo != nil {
+		if reply, err = res.redigo.unpackBytes(data); err != nil {
+			return "", err
+		}
+	}
+	return redis.String(reply, nil)
+}
+
+// IntResult is the handle of a queued command replying an integer
+type IntResult struct {
+	result
+}
+
+// Result returns the integer reply
+func (res *IntResult) Result() (int64, error) {
+	return redis.Int64(res.raw())
+}
+
+// FloatResult is the handle of a queued command replying a float number
+type FloatResult struct {
+	result
+}
+
+// Result returns the float reply
+func (res *FloatResult) Result() (float64, error) {
+	return redis.Float64(res.raw())
+}
+
+// BoolResult is the handle of a queued command replying an integer used as a boolean
+type BoolResult struct {
+	result
+}
+
+// Result returns the boolean reply
+func (res *BoolResult) Result() (bool, error) {
+	return redis.Bool(res.raw())
+}
+
+// StatusResult is the handle of a queued command replying a status such as OK
+type StatusResult struct {
+	result
+	check func(reply any) error
+}
+
+// Err returns the error of the command, including an unexpected status reply
+func (res *StatusResult) Err() error {
+	reply, err := res.raw()
+	if err != nil {
+		return err
+	}
+	return res.check(reply)
+}
+
+// ScanInto is the handle of a queued command whose reply is decoded into a destination like Get
+type ScanInto struct {
+	result
+	redigo *Redigo
+	v      any
+}
+
+func (res *ScanInto) setReply(reply any, err error) {
+	if err == nil {
+		err = res.redigo.scanReply(reply, res.v)
+	}
+	res.result.setReply(reply, err)
+}
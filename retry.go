@@ -0,0 +1,81 @@
+package redigo
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// RetryStrategy decides how long to wait before the next attempt of acquiring a lock
+type RetryStrategy interface {
+	// NextBackoff returns the delay before the retry number attempt (starting at 1),
+	// false to stop retrying
+	NextBackoff(attempt int) (time.Duration, bool)
+}
+
+type noRetry struct{}
+
+// NoRetry returns a strategy which never retries
+func NoRetry() RetryStrategy {
+	return noRetry{}
+}
+
+func (noRetry) NextBackoff(int) (time.Duration, bool) {
+	return 0, false
+}
+
+type fixedRetry struct {
+	interval time.Duration
+}
+
+// FixedRetry returns a strategy retrying every interval
+func FixedRetry(interval time.Duration) RetryStrategy {
+	return fixedRetry{interval: interval}
+}
+
+func (s fixedRetry) NextBackoff(int) (time.Duration, bool) {
+	return s.interval, true
+}
+
+type linearBackoff struct {
+	step time.Duration
+	max  time.Duration
+}
+
+// LinearBackoff returns a strategy waiting step more on each retry, up to max (no limit if max is not positive)
+func LinearBackoff(step, max time.Duration) RetryStrategy {
+	return linearBackoff{step: step, max: max}
+}
+
+func (s linearBackoff) NextBackoff(attempt int) (time.Duration, bool) {
+	backoff := s.step * time.Duration(attempt)
+	if s.max > 0 && (backoff > s.max || backoff < 0) {
+		backoff = s.max
+	}
+	return backoff, true
+}
+
+type exponentialBackoff struct {
+	min time.Duration
+	max time.Duration
+}
+
+// ExponentialBackoff returns a strategy doubling the delay from min on each retry, up to max,
+// with a random jitter of up to half of the delay so contending clients do not retry together
+func ExponentialBackoff(min, max time.Duration) RetryStrategy {
+	return exponentialBackoff{min: min, max: max}
+}
+
+func (s exponentialBackoff) NextBackoff(attempt int) (time.Duration, bool) {
+	backoff := s.max
+	if attempt < 32 {
+		if d := s.min << (attempt - 1); d > 0 && d < s.max {
+			backoff = d
+		}
+	}
+	// 在[backoff/2, backoff]之间随机, 避免惊群
+	half := backoff / 2
+	if half > 0 {
+		backoff = half + rand.N(half+1)
+	}
+	return backoff, true
+}
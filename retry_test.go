@@ -0,0 +1,31 @@
+package redigo
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetryStrategy(t *testing.T) {
+	if _, ok := NoRetry().NextBackoff(1); ok {
+		t.Fatal("NoRetry should not retry")
+	}
+	if d, ok := FixedRetry(50 * time.Millisecond).NextBackoff(10); !ok || d != 50*time.Millisecond {
+		t.Fatalf("FixedRetry returned %v %v", d, ok)
+	}
+
+	linear := LinearBackoff(10*time.Millisecond, 25*time.Millisecond)
+	if d, _ := linear.NextBackoff(2); d != 20*time.Millisecond {
+		t.Fatalf("LinearBackoff attempt 2 returned %v", d)
+	}
+	if d, _ := linear.NextBackoff(3); d != 25*time.Millisecond {
+		t.Fatalf("LinearBackoff attempt 3 returned %v", d)
+	}
+
+	exp := ExponentialBackoff(10*time.Millisecond, time.Second)
+	for attempt, max := range map[int]time.Duration{1: 10 * time.Millisecond, 4: 80 * time.Millisecond, 20: time.Second, 100: time.Second} {
+		d, ok := exp.NextBackoff(attempt)
+		if !ok || d < max/2 || d > max {
+			t.Fatalf("ExponentialBackoff attempt %d returned %v, expected within [%v, %v]", attempt, d, max/2, max)
+		}
+	}
+}
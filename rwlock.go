@@ -0,0 +1,176 @@
+package redigo
+
+import (
+	"context"
+	"sync"
+)
+
+// rlockScript adds the reader ARGV[1] to the readers (KEYS[2]) scored by the server time its lease
+// expires at, unless the lock is held by a writer (KEYS[1]) or a writer is waiting (KEYS[3]).
+// The expired readers are removed first. It returns 1 if the read lock was acquired, 0 otherwise
+var rlockScript = NewScript(3, `
+	if redis.call("EXISTS", KEYS[1]) == 1 or redis.call("EXISTS", KEYS[3]) == 1 then
+		return 0
+	end
+	local t = redis.call("TIME")
+	local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+	local expiry = tonumber(ARGV[2])
+	redis.call("ZREMRANGEBYSCORE", KEYS[2], "-inf", now)
+	redis.call("ZADD", KEYS[2], now + expiry, ARGV[1])
+	if redis.call("PTTL", KEYS[2]) < expiry then
+		redis.call("PEXPIRE", KEYS[2], expiry)
+	end
+	return 1
+`)
+
+// runlockScript removes the reader ARGV[1] and publishes the key ARGV[2] to the channel ARGV[3]
+// when the last reader is gone. It returns 0 if the reader lease had already expired
+var runlockScript = NewScript(1, `
+	local removed = redis.call("ZREM", KEYS[1], ARGV[1])
+	local t = redis.call("TIME")
+	redis.call("ZREMRANGEBYSCORE", KEYS[1], "-inf", tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000))
+	if redis.call("ZCARD", KEYS[1]) == 0 then
+		redis.call("PUBLISH", ARGV[3], ARGV[2])
+	end
+	return removed
+`)
+
+// wlockScript sets the writer (KEYS[1]) to ARGV[1] if there is neither a writer nor a live reader (KEYS[2]).
+// Otherwise the writer is marked as waiting (KEYS[3]) for ARGV[2] milliseconds so no new reader is admitted
+// until it gets the lock. It returns 1 if the write lock was acquired, 0 otherwise
+var wlockScript = NewScript(3, `
+	local t = redis.call("TIME")
+	local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+	redis.call("ZREMRANGEBYSCORE", KEYS[2], "-inf", now)
+	if redis.call("EXISTS", KEYS[1]) == 1 or redis.call("ZCARD", KEYS[2]) > 0 then
+		redis.call("SET", KEYS[3], ARGV[1], "PX", ARGV[2])
+		return 0
+	end
+	redis.call("SET", KEYS[1], ARGV[1], "PX", ARGV[2])
+	if redis.call("GET", KEYS[3]) == ARGV[1] then
+		redis.call("DEL", KEYS[3])
+	end
+	return 1
+`)
+
+// RWLock is a distributed read-write lock, it is held by any number of readers or by a single writer.
+// Every reader has its own lease which expires individually, and a waiting writer blocks new readers
+// so writers are not starved. The expiry, retry strategy and max attempts are set with the same
+// LockOption as LockContext
+type RWLock struct {
+	key     string
+	redigo  *Redigo
+	options *lockOptions
+
+	locker  sync.Mutex
+	readers []string
+	writer  string
+}
+
+// RWLock returns the read-write lock of key
+func (r *Redigo) RWLock(key string, opts ...LockOption) *RWLock {
+	return &RWLock{
+		key:     key,
+		redigo:  r,
+		options: parseLockOptions(opts...),
+	}
+}
+
+// Key returns the key of the lock
+func (l *RWLock) Key() string {
+	return l.key
+}
+
+// keys 返回写锁、读者集合和写等待标记的key
+func (l *RWLock) keys() (writer, readers, wait string) {
+	return l.key + ":writer", l.key + ":readers", l.key + ":wwait"
+}
+
+// RLock acquires a read lock, retrying while the lock is held or awaited by a writer.
+// It returns an error wrapping ErrLockAcquisitionFailed if ctx is done or the attempts are exhausted
+func (l *RWLock) RLock(ctx context.Context) error {
+	token, err := randomValue()
+	if err != nil {
+		return err
+	}
+	writer, readers, wait := l.keys()
+	err = l.redigo.acquire(ctx, l.key, l.options, func(ctx context.Context) (bool, error) {
+		result, err := l.redigo.evalScriptContext(ctx, rlockScript, writer, readers, wait, token, l.options.expiry.Milliseconds())
+		return result == int64(1), err
+	})
+	if err != nil {
+		return err
+	}
+
+	l.locker.Lock()
+	l.readers = append(l.readers, token)
+	l.locker.Unlock()
+	return nil
+}
+
+// RUnlock releases one read lock acquired by RLock, it returns ErrLockNotHeld if
+// there is no read lock to release or if its lease has expired
+func (l *RWLock) RUnlock() error {
+	l.locker.Lock()
+	defer l.locker.Unlock()
+	if len(l.readers) == 0 {
+		return ErrLockNotHeld
+	}
+	token := l.readers[len(l.readers)-1]
+	l.readers = l.readers[:len(l.readers)-1]
+
+	_, readers, _ := l.keys()
+	result, err := l.redigo.evalScript(runlockScript, readers, token, l.key, lockChannel(l.key))
+	if err != nil {
+		return err
+	}
+	if result == int64(0) {
+		return ErrLockNotHeld
+	}
+	return nil
+}
+
+// Lock acquires the write lock, retrying while the lock is held by a writer or by readers.
+// It returns an error wrapping ErrLockAcquisitionFailed if ctx is done or the attempts are exhausted
+func (l *RWLock) Lock(ctx context.Context) error {
+	token, err := randomValue()
+	if err != nil {
+		return err
+	}
+	writer, readers, wait := l.keys()
+	err = l.redigo.acquire(ctx, l.key, l.options, func(ctx context.Context) (bool, error) {
+		result, err := l.redigo.evalScriptContext(ctx, wlockScript, writer, readers, wait, token, l.options.expiry.Milliseconds())
+		return result == int64(1), err
+	})
+	if err != nil {
+		// 放弃等待时清除写等待标记, 让读者继续获取
+		_, _ = l.redigo.evalScript(unlockScript, wait, token, lockChannel(l.key))
+		return err
+	}
+
+	l.locker.Lock()
+	l.writer = token
+	l.locker.Unlock()
+	return nil
+}
+
+// Unlock releases the write lock, it returns ErrLockNotHeld if it is not held or has expired
+func (l *RWLock) Unlock() error {
+	l.locker.Lock()
+	defer l.locker.Unlock()
+	if l.writer == "" {
+		return ErrLockNotHeld
+	}
+	token := l.writer
+	l.writer = ""
+
+	writer, _, _ := l.keys()
+	result, err := l.redigo.evalScript(unlockScript, writer, token, lockChannel(l.key))
+	if err != nil {
+		return err
+	}
+	if result == int64(0) {
+		return ErrLockNotHeld
+	}
+	return nil
+}
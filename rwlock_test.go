@@ -0,0 +1,106 @@
+package redigo
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+const (
+	testRWLockKey = "test_rwlock_key"
+)
+
+func TestRedigo_RWLock(t *testing.T) {
+	redigo := NewRedigo(opts...)
+	_, _ = redigo.Del(testRWLockKey+":writer", testRWLockKey+":readers", testRWLockKey+":wwait")
+
+	lock := redigo.RWLock(testRWLockKey, WithLockExpiry(5*time.Second), WithMaxAttempts(3))
+	ctx := context.Background()
+
+	// 多个读者可同时持有
+	if err := lock.RLock(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := lock.RLock(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// 有读者时写锁失败
+	writer := redigo.RWLock(testRWLockKey, WithLockExpiry(5*time.Second), WithMaxAttempts(1))
+	if err := writer.Lock(ctx); !errors.Is(err, ErrLockAcquisitionFailed) {
+		t.Fatalf("expected ErrLockAcquisitionFailed, got %v", err)
+	}
+
+	// 写等待阻止新的读者, 读者全部释放后写者获取锁
+	waiting := redigo.RWLock(testRWLockKey, WithLockExpiry(5*time.Second))
+	acquired := make(chan error, 1)
+	go func() {
+		ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+		defer cancel()
+		acquired <- waiting.Lock(ctx)
+	}()
+	time.Sleep(100 * time.Millisecond)
+	if err := writer.RLock(ctx); !errors.Is(err, ErrLockAcquisitionFailed) {
+		t.Fatalf("expected reader blocked by waiting writer, got %v", err)
+	}
+
+	if err := lock.RUnlock(); err != nil {
+		t.Fatal(err)
+	}
+	if err := lock.RUnlock(); err != nil {
+		t.Fatal(err)
+	}
+	if err := lock.RUnlock(); !errors.Is(err, ErrLockNotHeld) {
+		t.Fatalf("expected ErrLockNotHeld, got %v", err)
+	}
+	if err := <-acquired; err != nil {
+		t.Fatal(err)
+	}
+	if err := waiting.Unlock(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := writer.Lock(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := lock.RLock(ctx); !errors.Is(err, ErrLockAcquisitionFailed) {
+		t.Fatalf("expected ErrLockAcquisitionFailed, got %v", err)
+	}
+	if err := writer.Unlock(); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Unlock(); !errors.Is(err, ErrLockNotHeld) {
+		t.Fatalf("expected ErrLockNotHeld, got %v", err)
+	}
+	if err := lock.RLock(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := lock.RUnlock(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRedigo_RWLockReaderExpiry(t *testing.T) {
+	redigo := NewRedigo(opts...)
+	_, _ = redigo.Del(testRWLockKey+":writer", testRWLockKey+":readers", testRWLockKey+":wwait")
+
+	// 读者租约过期后写者可获取锁
+	reader := redigo.RWLock(testRWLockKey, WithLockExpiry(300*time.Millisecond))
+	if err := reader.RLock(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	writer := redigo.RWLock(testRWLockKey, WithLockExpiry(5*time.Second))
+	if err := writer.Lock(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := reader.RUnlock(); !errors.Is(err, ErrLockNotHeld) {
+		t.Fatalf("expected ErrLockNotHeld, got %v", err)
+	}
+	if err := writer.Unlock(); err != nil {
+		t.Fatal(err)
+	}
+}
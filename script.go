@@ -0,0 +1,143 @@
+package redigo
+
+import (
+	"context"
+	"github.com/gomodule/redigo/redis"
+	"sync"
+)
+
+// Script is a Lua script executed with EVALSHA, it falls back to EVAL when the server replies NOSCRIPT
+type Script struct {
+	script *redis.Script
+	src    string
+}
+
+// NewScript returns a new script object. If keyCount is greater than or equal to zero, then the
+// count is automatically inserted in the EVAL command argument list. If keyCount is less than zero,
+// then the application supplies the count as the first value in the keysAndArgs argument
+func NewScript(keyCount int, src string) *Script {
+	return &Script{
+		script: redis.NewScript(keyCount, src),
+		src:    src,
+	}
+}
+
+// Hash returns the SHA1 hash of the script source
+func (s *Script) Hash() string {
+	return s.script.Hash()
+}
+
+// Source returns the script source
+func (s *Script) Source() string {
+	return s.src
+}
+
+// scriptRegistry 保存已注册的脚本, 新建连接时通过SCRIPT LOAD预加载
+type scriptRegistry struct {
+	locker  sync.RWMutex
+	scripts map[string]*Script
+}
+
+func newScriptRegistry(scripts ...*Script) *scriptRegistry {
+	reg := &scriptRegistry{
+		scripts: make(map[string]*Script),
+	}
+	reg.add(scripts...)
+	return reg
+}
+
+func (reg *scriptRegistry) add(scripts ...*Script) {
+	reg.locker.Lock()
+	defer reg.locker.Unlock()
+	for _, s := range scripts {
+		reg.scripts[s.Hash()] = s
+	}
+}
+
+func (reg *scriptRegistry) has(s *Script) bool {
+	reg.locker.RLock()
+	defer reg.locker.RUnlock()
+	_, ok := reg.scripts[s.Hash()]
+	return ok
+}
+
+// load 在一次往返中将所有已注册脚本加载到服务器, 返回第一个错误. ctx结束时中断等待并关闭连接
+func (reg *scriptRegistry) load(ctx context.Context, conn redis.Conn) error {
+	reg.locker.RLock()
+	defer reg.locker.RUnlock()
+	if len(reg.scripts) == 0 {
+		return nil
+	}
+	for _, s := range reg.scripts {
+		if err := conn.Send("SCRIPT", "LOAD", s.src); err != nil {
+			return err
+		}
+	}
+	if err := conn.Flush(); err != nil {
+		return err
+	}
+	// 读取全部响应, 单个脚本加载失败时连接仍可继续使用
+	var err error
+	for range reg.scripts {
+		if _, e := redis.ReceiveContext(conn, ctx); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// RegisterScript adds scripts to the registry, registered scripts are loaded with SCRIPT LOAD
+// immediately and on every new connection so EVALSHA rarely falls back to EVAL
+func (r *Redigo) RegisterScript(scripts ...*Script) error {
+	r.scripts.add(scripts...)
+
+	conn, err := r.getConn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	for _, s := range scripts {
+		if err = s.script.Load(conn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RunScript executes the script with EVALSHA (EVAL on NOSCRIPT) and decodes the reply into v like Get,
+// v can be nil to ignore the reply. A script not registered yet is added to the registry
+func (r *Redigo) RunScript(s *Script, v any, keysAndArgs ...any) error {
+	reply, err := r.evalScript(s, keysAndArgs...)
+	if err != nil {
+		return err
+	}
+	if v == nil {
+		return nil
+	}
+	return r.scanReply(reply, v)
+}
+
+// evalScript 执行脚本并返回原始响应
+func (r *Redigo) evalScript(s *Script, keysAndArgs ...any) (any, error) {
+	return r.evalScriptContext(context.Background(), s, keysAndArgs...)
+}
+
+// evalScriptContext 执行脚本并返回原始响应, ctx结束时中断等待
+func (r *Redigo) evalScriptContext(ctx context.Context, s *Script, keysAndArgs ...any) (any, error) {
+	conn, err := r.getConnContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	return r.evalScriptConn(ctx, conn, s, keysAndArgs...)
+}
+
+// evalScriptConn 在已取得的连接上执行脚本并返回原始响应
+func (r *Redigo) evalScriptConn(ctx context.Context, conn redis.Conn, s *Script, keysAndArgs ...any) (any, error) {
+	if !r.scripts.has(s) {
+		r.scripts.add(s)
+	}
+	return s.script.DoContext(ctx, conn, keysAndArgs...)
+}
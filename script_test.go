@@ -0,0 +1,41 @@
+package redigo
+
+import (
+	"testing"
+)
+
+const (
+	redigoScriptKey = "redigoScriptKey"
+)
+
+var testScript = NewScript(1, `
+	redis.call("SET", KEYS[1], ARGV[1])
+	return {KEYS[1], redis.call("GET", KEYS[1])}
+`)
+
+func TestRedigo_RunScript(t *testing.T) {
+	redigo := NewRedigo(append(opts, WithScripts(testScript))...)
+
+	var values []string
+	err := redigo.RunScript(testScript, &values, redigoScriptKey, "hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(values) != 2 || values[1] != "hello" {
+		t.Fatalf("unexpected script result %+v", values)
+	}
+
+	// 清空脚本缓存后EVALSHA返回NOSCRIPT, 自动回退到EVAL
+	if _, err = redigo.Do("SCRIPT", "FLUSH"); err != nil {
+		t.Fatal(err)
+	}
+	var n int64
+	err = redigo.RunScript(NewScript(0, `return 10086`), &n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 10086 {
+		t.Fatalf("unexpected script result %v", n)
+	}
+	t.Logf("script %s result %+v", testScript.Hash(), values)
+}
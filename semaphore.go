@@ -0,0 +1,256 @@
+package redigo
+
+import (
+	"context"
+	"fmt"
+	"github.com/gomodule/redigo/redis"
+	"sync"
+	"time"
+)
+
+// semaphoreCleanup removes the holders whose lease expired, KEYS[1] is the sorted set of holders
+// scored by the server time their lease expires at and KEYS[2] the hash of their permits.
+// It defines now, the server time in milliseconds
+const semaphoreCleanup = `
+	local t = redis.call("TIME")
+	local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+	for _, holder in ipairs(redis.call("ZRANGEBYSCORE", KEYS[1], "-inf", now)) do
+		redis.call("ZREM", KEYS[1], holder)
+		redis.call("HDEL", KEYS[2], holder)
+	end
+`
+
+// semaphoreAcquireScript adds the holder ARGV[1] with ARGV[2] permits and a lease of ARGV[4] milliseconds
+// if the permits in use do not exceed ARGV[3] afterward. It returns 1 if acquired, 0 otherwise
+var semaphoreAcquireScript = NewScript(2, semaphoreCleanup+`
+	local used = 0
+	for _, permits in ipairs(redis.call("HVALS", KEYS[2])) do
+		used = used + tonumber(permits)
+	end
+	if used + tonumber(ARGV[2]) > tonumber(ARGV[3]) then
+		return 0
+	end
+	local expiry = tonumber(ARGV[4])
+	redis.call("ZADD", KEYS[1], now + expiry, ARGV[1])
+	redis.call("HSET", KEYS[2], ARGV[1], ARGV[2])
+	for _, key in ipairs(KEYS) do
+		if redis.call("PTTL", key) < expiry then
+			redis.call("PEXPIRE", key, expiry)
+		end
+	end
+	return 1
+`)
+
+// semaphoreReleaseScript removes the holder ARGV[1] and publishes ARGV[2] to the channel ARGV[3].
+// It returns 0 if the holder lease had already expired
+var semaphoreReleaseScript = NewScript(2, semaphoreCleanup+`
+	local removed = redis.call("ZREM", KEYS[1], ARGV[1])
+	redis.call("HDEL", KEYS[2], ARGV[1])
+	redis.call("PUBLISH", ARGV[3], ARGV[2])
+	return removed
+`)
+
+// semaphoreExtendScript resets the lease of the holder ARGV[1] to ARGV[2] milliseconds, 0 if it expired
+var semaphoreExtendScript = NewScript(2, semaphoreCleanup+`
+	if not redis.call("ZSCORE", KEYS[1], ARGV[1]) then
+		return 0
+	end
+	local expiry = tonumber(ARGV[2])
+	redis.call("ZADD", KEYS[1], now + expiry, ARGV[1])
+	for _, key in ipairs(KEYS) do
+		if redis.call("PTTL", key) < expiry then
+			redis.call("PEXPIRE", key, expiry)
+		end
+	end
+	return 1
+`)
+
+// semaphoreHoldersScript returns the live holders as {holder, permits, lease end in milliseconds, ...}
+var semaphoreHoldersScript = NewScript(2, semaphoreCleanup+`
+	local holders = {}
+	local entries = redis.call("ZRANGE", KEYS[1], 0, -1, "WITHSCORES")
+	for i = 1, #entries, 2 do
+		table.insert(holders, entries[i])
+		table.insert(holders, redis.call("HGET", KEYS[2], entries[i]) or "0")
+		table.insert(holders, entries[i + 1])
+	end
+	return holders
+`)
+
+// Semaphore is a distributed counting semaphore limiting the permits in use to its size.
+// Every acquisition holds its permits with a lease, the permits of a crashed holder are
+// reclaimed when the lease expires. The expiry, retry strategy and max attempts are set
+// with the same LockOption as LockContext
+type Semaphore struct {
+	key     string
+	size    int64
+	redigo  *Redigo
+	options *lockOptions
+}
+
+// SemaphoreHolder is a holder of semaphore permits
+type SemaphoreHolder struct {
+	ID      string    // identifier of the holder, see Permit.ID
+	Permits int64     // number of permits held
+	Expires time.Time // end of the lease, in server time
+}
+
+// Permit is a hold on permits of a Semaphore
+type Permit struct {
+	id        string
+	permits   int64
+	semaphore *Semaphore
+
+	locker   sync.Mutex
+	released bool
+}
+
+// Semaphore returns the semaphore of key allowing size permits in use
+func (r *Redigo) Semaphore(key string, size int64, opts ...LockOption) *Semaphore {
+	return &Semaphore{
+		key:     key,
+		size:    size,
+		redigo:  r,
+		options: parseLockOptions(opts...),
+	}
+}
+
+// Key returns the key of the semaphore
+func (s *Semaphore) Key() string {
+	return s.key
+}
+
+// Size returns the number of permits of the semaphore
+func (s *Semaphore) Size() int64 {
+	return s.size
+}
+
+// keys 返回持有者集合和许可数哈希的key
+func (s *Semaphore) keys() (holders, permits string) {
+	return s.key + ":holders", s.key + ":permits"
+}
+
+// Acquire acquires permits, retrying while not enough permits are available until ctx is done or
+// WithMaxAttempts is reached, in which case an error wrapping ErrLockAcquisitionFailed is returned
+func (s *Semaphore) Acquire(ctx context.Context, permits int64) (*Permit, error) {
+	return s.acquire(ctx, permits, s.options)
+}
+
+// TryAcquire acquires permits without waiting, it returns ErrLockAcquisitionFailed if not enough permits are available
+func (s *Semaphore) TryAcquire(permits int64) (*Permit, error) {
+	options := *s.options
+	options.maxAttempts = 1
+	return s.acquire(context.Background(), permits, &options)
+}
+
+func (s *Semaphore) acquire(ctx context.Context, permits int64, options *lockOptions) (*Permit, error) {
+	if permits <= 0 || permits > s.size {
+		return nil, fmt.Errorf("%w: %d permits of %d", ErrInvalidPermits, permits, s.size)
+	}
+	id, err := randomValue()
+	if err != nil {
+		return nil, err
+	}
+
+	holders, permitsKey := s.keys()
+	err = s.redigo.acquire(ctx, s.key, options, func(ctx context.Context) (bool, error) {
+		result, err := s.redigo.evalScriptContext(ctx, semaphoreAcquireScript, holders, permitsKey,
+			id, permits, s.size, options.expiry.Milliseconds())
+		return result == int64(1), err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Permit{
+		id:        id,
+		permits:   permits,
+		semaphore: s,
+	}, nil
+}
+
+// Holders returns the holders whose lease has not expired
+func (s *Semaphore) Holders() ([]SemaphoreHolder, error) {
+	holdersKey, permitsKey := s.keys()
+	values, err := redis.Values(s.redigo.evalScript(semaphoreHoldersScript, holdersKey, permitsKey))
+	if err != nil {
+		return nil, err
+	}
+	if len(values)%3 != 0 {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidResponse, values)
+	}
+
+	var holders = make([]SemaphoreHolder, 0, len(values)/3)
+	for i := 0; i < len(values); i += 3 {
+		id, err := redis.String(values[i], nil)
+		if err != nil {
+			return nil, err
+		}
+		permits, err := redis.Int64(values[i+1], nil)
+		if err != nil {
+			return nil, err
+		}
+		expires, err := redis.Int64(values[i+2], nil)
+		if err != nil {
+			return nil, err
+		}
+		holders = append(holders, SemaphoreHolder{
+			ID:      id,
+			Permits: permits,
+			Expires: time.UnixMilli(expires),
+		})
+	}
+	return holders, nil
+}
+
+// ID returns the identifier of the holder
+func (p *Permit) ID() string {
+	return p.id
+}
+
+// Permits returns the number of permits held
+func (p *Permit) Permits() int64 {
+	return p.permits
+}
+
+// Release returns the permits to the semaphore, it returns ErrLockNotHeld if they were
+// already released or their lease has expired
+func (p *Permit) Release() error {
+	p.locker.Lock()
+	defer p.locker.Unlock()
+	if p.released {
+		return ErrLockNotHeld
+	}
+
+	s := p.semaphore
+	holders, permits := s.keys()
+	result, err := s.redigo.evalScript(semaphoreReleaseScript, holders, permits, p.id, s.key, lockChannel(s.key))
+	if err != nil {
+		return err
+	}
+	p.released = true
+	if result == int64(0) {
+		return ErrLockNotHeld
+	}
+	return nil
+}
+
+// Extend resets the lease of the permits to d from now, it returns ErrLockNotHeld if they were
+// already released or their lease has expired
+func (p *Permit) Extend(d time.Duration) error {
+	p.locker.Lock()
+	defer p.locker.Unlock()
+	if p.released {
+		return ErrLockNotHeld
+	}
+
+	s := p.semaphore
+	holders, permits := s.keys()
+	result, err := s.redigo.evalScript(semaphoreExtendScript, holders, permits, p.id, d.Milliseconds())
+	if err != nil {
+		return err
+	}
+	if result == int64(0) {
+		return ErrLockNotHeld
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package redigo
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+const (
+	testSemaphoreKey = "test_semaphore_key"
+)
+
+func TestRedigo_Semaphore(t *testing.T) {
+	redigo := NewRedigo(opts...)
+	sem := redigo.Semaphore(testSemaphoreKey, 3, WithLockExpiry(5*time.Second))
+	holdersKey, permitsKey := sem.keys()
+	_, _ = redigo.Del(holdersKey, permitsKey)
+
+	p1, err := sem.Acquire(context.Background(), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p2, err := sem.TryAcquire(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = sem.TryAcquire(1); !errors.Is(err, ErrLockAcquisitionFailed) {
+		t.Fatalf("expected ErrLockAcquisitionFailed, got %v", err)
+	}
+	if _, err = sem.TryAcquire(4); !errors.Is(err, ErrInvalidPermits) {
+		t.Fatalf("expected ErrInvalidPermits, got %v", err)
+	}
+
+	holders, err := sem.Holders()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(holders) != 2 {
+		t.Fatalf("expected 2 holders, got %+v", holders)
+	}
+	for _, holder := range holders {
+		if holder.ID == p1.ID() && holder.Permits != 2 || holder.ID == p2.ID() && holder.Permits != 1 {
+			t.Fatalf("unexpected holder %+v", holder)
+		}
+	}
+
+	// 释放后等待者被唤醒
+	go func() {
+		time.Sleep(200 * time.Millisecond)
+		_ = p1.Release()
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	p3, err := sem.Acquire(ctx, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = p1.Release(); !errors.Is(err, ErrLockNotHeld) {
+		t.Fatalf("expected ErrLockNotHeld, got %v", err)
+	}
+	if err = p2.Extend(10 * time.Second); err != nil {
+		t.Fatal(err)
+	}
+	_ = p2.Release()
+	_ = p3.Release()
+}
+
+func TestRedigo_SemaphoreExpiry(t *testing.T) {
+	redigo := NewRedigo(opts...)
+	sem := redigo.Semaphore(testSemaphoreKey, 1, WithLockExpiry(300*time.Millisecond))
+	holdersKey, permitsKey := sem.keys()
+	_, _ = redigo.Del(holdersKey, permitsKey)
+
+	// 崩溃的持有者租约过期后许可被回收
+	crashed, err := sem.TryAcquire(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	p, err := sem.Acquire(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = crashed.Release(); !errors.Is(err, ErrLockNotHeld) {
+		t.Fatalf("expected ErrLockNotHeld, got %v", err)
+	}
+	if err = p.Release(); err != nil {
+		t.Fatal(err)
+	}
+}
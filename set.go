@@ -0,0 +1,53 @@
+package redigo
+
+import (
+	"github.com/gomodule/redigo/redis"
+)
+
+// SAdd adds members to the set key and returns the number of members which were added.
+// The members are encoded like Set. With WithEncryption the same member gives a different ciphertext
+// on every write, so the members of a set must be written and looked up by a client returned by
+// WithDeterministicEncryption for SAdd to deduplicate them and for SIsMember and SRem to find them.
+// The deterministic ciphertext depends on the primary key: after the keyring's primary key changes,
+// run ReencryptKeys so the members written with the previous key are found again
+func (r *Redigo) SAdd(key string, members ...any) (int64, error) {
+	args, err := r.encodeArgs(key, members...)
+	if err != nil {
+		return 0, err
+	}
+	return r.intCmd("SADD", args...)
+}
+
+// SIsMember reports whether member, encoded like SAdd, belongs to the set key
+func (r *Redigo) SIsMember(key string, member any) (bool, error) {
+	data, err := r.encodeValue(member)
+	if err != nil {
+		return false, err
+	}
+	n, err := r.intCmd("SISMEMBER", key, data)
+	return n == 1, err
+}
+
+// SRem removes members, encoded like SAdd, from the set key and returns the number of members which were removed
+func (r *Redigo) SRem(key string, members ...any) (int64, error) {
+	args, err := r.encodeArgs(key, members...)
+	if err != nil {
+		return 0, err
+	}
+	return r.intCmd("SREM", args...)
+}
+
+// SMembers decodes the members of the set key into v, a pointer to slice, like ListRange
+func (r *Redigo) SMembers(key string, v any) error {
+	conn, err := r.getConn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	values, err := redis.Values(conn.Do("SMEMBERS", key))
+	if err != nil {
+		return err
+	}
+	return r.scanValues(values, v)
+}
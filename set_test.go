@@ -0,0 +1,75 @@
+package redigo
+
+import (
+	"context"
+	"testing"
+)
+
+const (
+	redigoSetKey = "redigoSetKey"
+)
+
+func TestRedigo_SetMembers(t *testing.T) {
+	redigo := NewRedigo(opts...)
+	_, _ = redigo.Del(redigoSetKey)
+
+	n, err := redigo.SAdd(redigoSetKey, &User{ID: 10086, Name: "dianxin"}, &User{ID: 10010, Name: "liantong"})
+	if err != nil || n != 2 {
+		t.Fatalf("SADD returned %v %v", n, err)
+	}
+	ok, err := redigo.SIsMember(redigoSetKey, &User{ID: 10086, Name: "dianxin"})
+	if err != nil || !ok {
+		t.Fatalf("expected member, got %v (%v)", ok, err)
+	}
+	if n, err = redigo.SRem(redigoSetKey, &User{ID: 10086, Name: "dianxin"}); err != nil || n != 1 {
+		t.Fatalf("SREM returned %v %v", n, err)
+	}
+	var users []User
+	if err = redigo.SMembers(redigoSetKey, &users); err != nil || len(users) != 1 || users[0].ID != 10010 {
+		t.Fatalf("unexpected SMEMBERS result %+v (%v)", users, err)
+	}
+}
+
+func TestRedigo_SetEncryption(t *testing.T) {
+	keyring := NewKeyring()
+	if err := keyring.AddKey("k1", newTestKey(t)); err != nil {
+		t.Fatal(err)
+	}
+	// 集合成员需要确定性加密才能去重和查找
+	redigo := NewRedigo(append(opts, WithEncryption(keyring))...).WithDeterministicEncryption()
+	_, _ = redigo.Del(redigoSetKey)
+
+	if n, err := redigo.SAdd(redigoSetKey, "a", "b", "a"); err != nil || n != 2 {
+		t.Fatalf("SADD returned %v %v", n, err)
+	}
+	if ok, err := redigo.SIsMember(redigoSetKey, "b"); err != nil || !ok {
+		t.Fatalf("expected member, got %v (%v)", ok, err)
+	}
+	if n, err := redigo.SRem(redigoSetKey, "a"); err != nil || n != 1 {
+		t.Fatalf("SREM returned %v %v", n, err)
+	}
+	var members []string
+	if err := redigo.SMembers(redigoSetKey, &members); err != nil || len(members) != 1 || members[0] != "b" {
+		t.Fatalf("unexpected SMEMBERS result %v (%v)", members, err)
+	}
+
+	// 轮换主密钥后重新加密成员, 之后仍能查找和删除
+	if err := keyring.AddKey("k2", newTestKey(t)); err != nil {
+		t.Fatal(err)
+	}
+	if err := keyring.SetPrimary("k2"); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := redigo.SIsMember(redigoSetKey, "b"); err != nil || ok {
+		t.Fatalf("expected the member encrypted with k1 to be missed, got %v (%v)", ok, err)
+	}
+	if n, err := redigo.ReencryptKeys(context.Background(), redigoSetKey); err != nil || n != 1 {
+		t.Fatalf("expected 1 re-encrypted member, got %d (%v)", n, err)
+	}
+	if ok, err := redigo.SIsMember(redigoSetKey, "b"); err != nil || !ok {
+		t.Fatalf("expected member, got %v (%v)", ok, err)
+	}
+	if n, err := redigo.SRem(redigoSetKey, "b"); err != nil || n != 1 {
+		t.Fatalf("SREM returned %v %v", n, err)
+	}
+}
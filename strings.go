@@ -0,0 +1,133 @@
+package redigo
+
+import (
+	"time"
+)
+
+// GetEx reads the value of key into v like Get and sets its expiry,
+// a zero expiration removes the existing expiry (PERSIST)
+func (r *Redigo) GetEx(key string, v any, expiration time.Duration) error {
+	var args = []any{key}
+	if expiration == 0 {
+		args = append(args, "PERSIST")
+	} else if expiration%time.Second == 0 {
+		args = append(args, "EX", int64(expiration/time.Second))
+	} else {
+		args = append(args, "PX", expiration.Milliseconds())
+	}
+	return r.getInto(v, "GETEX", args...)
+}
+
+// GetExAt reads the value of key into v like Get and sets it to expire at tm
+func (r *Redigo) GetExAt(key string, v any, tm time.Time) error {
+	return r.getInto(v, "GETEX", key, "PXAT", tm.UnixMilli())
+}
+
+// GetDel reads the value of key into v like Get and deletes the key
+func (r *Redigo) GetDel(key string, v any) error {
+	return r.getInto(v, "GETDEL", key)
+}
+
+// GetSet sets the value of key and decodes the old value into old,
+// it returns redis.ErrNil if the key did not exist (the new value is still set)
+func (r *Redigo) GetSet(key string, v any, old any) error {
+	data, err := r.encodeValue(v)
+	if err != nil {
+		return err
+	}
+	return r.getInto(old, "GETSET", key, data)
+}
+
+// getInto 执行返回单个值的命令并解码到v, nil响应返回redis.ErrNil
+func (r *Redigo) getInto(v any, cmd string, args ...any) error {
+	conn, err := r.getConn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	reply, err := conn.Do(cmd, args...)
+	if err != nil {
+		return err
+	}
+	return r.scanReply(reply, v)
+}
+
+// SetNX sets the value of key only if it does not exist, a zero expiration means no expiry.
+// It returns false if the key already exists
+func (r *Redigo) SetNX(key string, v any, expiration time.Duration) (bool, error) {
+	var opts = []SetOption{WithNX()}
+	if expiration != 0 {
+		opts = append(opts, WithExpiration(expiration))
+	}
+	options := parseSetOptions(opts...)
+	args, err := r.setArgs(key, v, options)
+	if err != nil {
+		return false, err
+	}
+
+	conn, err := r.getConn()
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+
+	reply, err := conn.Do("SET", args...)
+	if err != nil {
+		return false, err
+	}
+	if reply == nil {
+		return false, nil
+	}
+	if err = checkOK(reply, nil); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Append appends value at the end of the string stored at key and returns the new length
+func (r *Redigo) Append(key string, value string) (int64, error) {
+	return r.intCmd("APPEND", key, value)
+}
+
+// GetRange returns the substring of the string stored at key between start and end (both inclusive)
+func (r *Redigo) GetRange(key string, start, end int64) (string, error) {
+	var s string
+	if err := r.getInto(&s, "GETRANGE", key, start, end); err != nil {
+		return "", err
+	}
+	return s, nil
+}
+
+// SetRange overwrites part of the string stored at key starting at offset and returns the new length
+func (r *Redigo) SetRange(key string, offset int64, value string) (int64, error) {
+	return r.intCmd("SETRANGE", key, offset, value)
+}
+
+// StrLen returns the length of the string stored at key, 0 if the key does not exist
+func (r *Redigo) StrLen(key string) (int64, error) {
+	return r.intCmd("STRLEN", key)
+}
+
+// LCS returns the longest common subsequence of the strings stored at key1 and key2
+func (r *Redigo) LCS(key1, key2 string) (string, error) {
+	var s string
+	if err := r.getInto(&s, "LCS", key1, key2); err != nil {
+		return "", err
+	}
+	return s, nil
+}
+
+// LCSLen returns the length of the longest common subsequence of the strings stored at key1 and key2
+func (r *Redigo) LCSLen(key1, key2 string) (int64, error) {
+	return r.intCmd("LCS", key1, key2, "LEN")
+}
+
+// intCmd 执行返回整数的命令
+func (r *Redigo) intCmd(cmd string, args ...any) (int64, error) {
+	var n int64
+	if err := r.getInto(&n, cmd, args...); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
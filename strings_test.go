@@ -0,0 +1,91 @@
+package redigo
+
+import (
+	"errors"
+	"github.com/gomodule/redigo/redis"
+	"testing"
+	"time"
+)
+
+const (
+	redigoStringKey = "redigoStringKey"
+)
+
+func TestRedigo_SetOptions(t *testing.T) {
+	redigo := NewRedigo(opts...)
+	_, err := redigo.Del(redigoStringKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = redigo.Set(redigoStringKey, &User{ID: 10086, Name: "dianxin"}, WithExpiration(1500*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var old User
+	err = redigo.Set(redigoStringKey, &User{ID: 10010, Name: "liantong"}, WithKeepTTL(), WithGET(&old))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if old.ID != 10086 {
+		t.Fatalf("unexpected old value %+v", old)
+	}
+	ttl, err := redigo.PTTL(redigoStringKey)
+	if err != nil || ttl <= 0 {
+		t.Fatalf("KEEPTTL lost expiry: %v %v", ttl, err)
+	}
+
+	err = redigo.Set(redigoStringKey, "value", WithPXAT(time.Now().Add(time.Minute)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ok, err := redigo.SetNX(redigoStringKey, "value", time.Minute)
+	if err != nil || ok {
+		t.Fatalf("SETNX returned %v %v", ok, err)
+	}
+}
+
+func TestRedigo_Strings(t *testing.T) {
+	redigo := NewRedigo(opts...)
+	_, err := redigo.Del(redigoStringKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var old string
+	if err = redigo.GetSet(redigoStringKey, "hello", &old); !errors.Is(err, redis.ErrNil) {
+		t.Fatalf("expected redis.ErrNil, got %v", err)
+	}
+	n, err := redigo.Append(redigoStringKey, " world")
+	if err != nil || n != 11 {
+		t.Fatalf("APPEND returned %v %v", n, err)
+	}
+	s, err := redigo.GetRange(redigoStringKey, 0, 4)
+	if err != nil || s != "hello" {
+		t.Fatalf("GETRANGE returned %v %v", s, err)
+	}
+	n, err = redigo.SetRange(redigoStringKey, 6, "redis")
+	if err != nil || n != 11 {
+		t.Fatalf("SETRANGE returned %v %v", n, err)
+	}
+	n, err = redigo.StrLen(redigoStringKey)
+	if err != nil || n != 11 {
+		t.Fatalf("STRLEN returned %v %v", n, err)
+	}
+
+	if err = redigo.GetEx(redigoStringKey, &s, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if ttl, _ := redigo.TTL(redigoStringKey); ttl <= 0 {
+		t.Fatalf("GETEX did not set expiry, TTL %v", ttl)
+	}
+	if err = redigo.GetDel(redigoStringKey, &s); err != nil {
+		t.Fatal(err)
+	}
+	if s != "hello redis" {
+		t.Fatalf("GETDEL returned %v", s)
+	}
+	if err = redigo.Get(redigoStringKey, &s); !errors.Is(err, redis.ErrNil) {
+		t.Fatalf("expected redis.ErrNil after GETDEL, got %v", err)
+	}
+}
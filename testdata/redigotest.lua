@@ -0,0 +1,13 @@
+#!lua name=redigotest
+
+redis.register_function('redigo_echo', function(keys, args)
+  return args[1]
+end)
+
+redis.register_function{
+  function_name = 'redigo_get',
+  callback = function(keys, args)
+    return redis.call('GET', keys[1])
+  end,
+  flags = { 'no-writes' },
+}
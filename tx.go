@@ -0,0 +1,182 @@
+package redigo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gomodule/redigo/redis"
+)
+
+// Tx is a MULTI/EXEC transaction on one pooled connection.
+// Commands queued by the typed methods (Get/Set/Incr/...) are sent after MULTI and their handles
+// are filled from the EXEC reply. Query and Fetch run immediately and can only be used before
+// the first command is queued, which is the read phase of an optimistic transaction (see Watch)
+type Tx struct {
+	commandQueue
+}
+
+// Tx returns a new transaction holding a connection from the pool until Close is called
+func (r *Redigo) Tx() (*Tx, error) {
+	conn, err := r.getConn()
+	if err != nil {
+		return nil, err
+	}
+	return newTx(r, conn), nil
+}
+
+func newTx(r *Redigo, conn redis.Conn) *Tx {
+	return &Tx{
+		commandQueue: commandQueue{
+			redigo: r,
+			conn:   conn,
+			multi:  true,
+		},
+	}
+}
+
+// Query executes a command immediately, it fails with ErrTxQueued once commands are queued
+func (tx *Tx) Query(cmd string, args ...any) (any, error) {
+	if tx.inMulti {
+		return nil, ErrTxQueued
+	}
+	return tx.conn.Do(cmd, args...)
+}
+
+// Fetch reads the value of key immediately and decodes it into v like Redigo.Get
+func (tx *Tx) Fetch(key string, v any) error {
+	reply, err := tx.Query("GET", key)
+	if err != nil {
+		return err
+	}
+	return tx.redigo.scanReply(reply, v)
+}
+
+// Exec executes the queued commands atomically and decodes the EXEC reply into their handles.
+// It returns ErrTxAborted when a watched key was changed, and the EXECABORT error when a command
+// was rejected while queuing. An error replied by a single command is reported by its handle only
+func (tx *Tx) Exec() error {
+	if !tx.inMulti {
+		return nil
+	}
+	pending := tx.pending
+	tx.pending = nil
+	tx.inMulti = false
+
+	if err := tx.conn.Send("EXEC"); err != nil {
+		tx.pending = pending
+		tx.fail(err)
+		return err
+	}
+	if err := tx.conn.Flush(); err != nil {
+		tx.pending = pending
+		tx.fail(err)
+		return err
+	}
+	// MULTI的OK和每条命令的QUEUED响应
+	var queueErrs = make([]error, len(pending))
+	for i := 0; i <= len(pending); i++ {
+		_, err := tx.conn.Receive()
+		var redisErr redis.Error
+		if err != nil && !errors.As(err, &redisErr) {
+			tx.pending = pending
+			tx.fail(err)
+			return err
+		}
+		if i > 0 {
+			queueErrs[i-1] = err
+		}
+	}
+
+	reply, err := tx.conn.Receive()
+	if err != nil {
+		for i, res := range pending {
+			if queueErrs[i] != nil {
+				res.setReply(nil, queueErrs[i])
+			} else {
+				res.setReply(nil, err)
+			}
+		}
+		return err
+	}
+	if reply == nil {
+		tx.pending = pending
+		tx.fail(ErrTxAborted)
+		return ErrTxAborted
+	}
+	values, err := redis.Values(reply, nil)
+	if err != nil {
+		tx.pending = pending
+		tx.fail(err)
+		return err
+	}
+	if len(values) != len(pending) {
+		err = fmt.Errorf("%w: EXEC returned %d replies for %d commands", ErrInvalidResponse, len(values), len(pending))
+		tx.pending = pending
+		tx.fail(err)
+		return err
+	}
+	for i, res := range pending {
+		if e, ok := values[i].(redis.Error); ok {
+			res.setReply(nil, e)
+		} else {
+			res.setReply(values[i], nil)
+		}
+	}
+	return nil
+}
+
+// Discard drops the queued commands, the handles report ErrTxAborted
+func (tx *Tx) Discard() error {
+	if !tx.inMulti {
+		return nil
+	}
+	tx.inMulti = false
+	tx.fail(ErrTxAborted)
+	_, err := tx.conn.Do("DISCARD")
+	return err
+}
+
+// Close discards the commands not executed yet and returns the connection to the pool
+func (tx *Tx) Close() error {
+	_ = tx.Discard()
+	return tx.conn.Close()
+}
+
+// Watch runs fn as an optimistic transaction on one pooled connection.
+// keys are watched before fn is called, fn reads with tx.Query/tx.Fetch and then queues the writes,
+// which are executed by Watch. When a watched key is changed before EXEC the whole transaction is
+// retried, up to WithTxRetries attempts, after which ErrTxFailed is returned.
+// An error returned by fn aborts the transaction and is returned as is
+func (r *Redigo) Watch(ctx context.Context, keys []string, fn func(tx *Tx) error, opts ...TxOption) error {
+	options := parseTxOptions(opts...)
+	conn, err := r.getConn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	for i := 0; i < options.retries; i++ {
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+		if _, err = conn.Do("WATCH", redis.Args{}.AddFlat(keys)...); err != nil {
+			return err
+		}
+		tx := newTx(r, conn)
+		if err = fn(tx); err != nil {
+			_ = tx.Discard()
+			_, _ = conn.Do("UNWATCH")
+			return err
+		}
+		if tx.Len() == 0 {
+			_, err = conn.Do("UNWATCH")
+			return err
+		}
+		err = tx.Exec()
+		if errors.Is(err, ErrTxAborted) {
+			continue
+		}
+		return err
+	}
+	return ErrTxFailed
+}
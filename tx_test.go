@@ -0,0 +1,84 @@
+package redigo
+
+import (
+	"context"
+	"errors"
+	"github.com/gomodule/redigo/redis"
+	"sync"
+	"testing"
+)
+
+const (
+	redigoTxKey = "redigoTxKey"
+)
+
+func TestRedigo_Tx(t *testing.T) {
+	redigo := NewRedigo(opts...)
+	tx, err := redigo.Tx()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Close()
+
+	del := tx.Del(redigoTxKey)
+	incr := tx.IncrBy(redigoTxKey, 5)
+	get := tx.Get(redigoTxKey)
+	if _, err = tx.Query("GET", redigoTxKey); !errors.Is(err, ErrTxQueued) {
+		t.Fatalf("expected ErrTxQueued, got %v", err)
+	}
+	if err = tx.Exec(); err != nil {
+		t.Fatal(err)
+	}
+	if err = del.Err(); err != nil {
+		t.Fatal(err)
+	}
+	n, err := incr.Result()
+	if err != nil || n != 5 {
+		t.Fatalf("INCRBY returned %v %v", n, err)
+	}
+	s, err := get.Result()
+	if err != nil || s != "5" {
+		t.Fatalf("GET returned %v %v", s, err)
+	}
+}
+
+func TestRedigo_Watch(t *testing.T) {
+	redigo := NewRedigo(opts...)
+	_, err := redigo.Del(redigoTxKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const workers = 10
+	var wg sync.WaitGroup
+	var errs = make(chan error, workers)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- redigo.Watch(context.Background(), []string{redigoTxKey}, func(tx *Tx) error {
+				var n int64
+				if err := tx.Fetch(redigoTxKey, &n); err != nil && !errors.Is(err, redis.ErrNil) {
+					return err
+				}
+				tx.Set(redigoTxKey, n+1)
+				return nil
+			}, WithTxRetries(100))
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err = range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var n int64
+	if err = redigo.Get(redigoTxKey, &n); err != nil {
+		t.Fatal(err)
+	}
+	if n != workers {
+		t.Fatalf("expected counter %d, got %d", workers, n)
+	}
+}